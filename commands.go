@@ -0,0 +1,545 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"shortcutter/internal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// commands maps subcommand names to their handlers, which return the exit code.
+var commands = map[string]func(args []string) int{
+	"list":   runList,
+	"show":   runShow,
+	"add":    runAdd,
+	"remove": runRemove,
+	"config": runConfig,
+	"init":   runInit,
+	"stats":  runStats,
+	"learn":  runLearn,
+	"tip":    runTip,
+	"export": runExport,
+	"sheet":  runSheet,
+}
+
+const commandsHelp = `Commands:
+  list                 print all shortcuts as a table
+  show <key>           print the resolved fields of one shortcut
+  add <key> [flags]    add or replace a shortcut in the config file
+  remove <key>         remove a shortcut from the config file
+  config validate      check the config file for mistakes
+  init <shell>         print the zsh, bash or fish integration script
+  stats                report which shortcuts you pick, and which you never do
+  learn                practise key bindings with spaced-repetition flashcards
+  tip [buffer]         print one shortcut worth learning, e.g. from precmd
+  export --format f    write a cheat sheet as Markdown, HTML or a man page
+  sheet                print every shortcut in columns across the terminal
+
+Run without a command to open the picker.
+`
+
+// usage prints the flags for a command along with the list of commands.
+func usage(flags *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s:\n", flags.Name())
+		flags.PrintDefaults()
+		if flags.Name() == "shortcutter" {
+			fmt.Fprintf(flags.Output(), "\n%s", commandsHelp)
+		}
+	}
+}
+
+// parseArgs parses flags given before or after the positional arguments.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// loadCommandShortcuts loads the merged shortcuts using any shell state
+// passed through the flags registered by shellStateFlags.
+func loadCommandShortcuts(bindingsPath, aliasesPath, functionsPath string) ([]internal.Shortcut, error) {
+	state, err := readShellState(bindingsPath, aliasesPath, functionsPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading shell state: %w", err)
+	}
+	return internal.LoadShortcutsFromShell(state)
+}
+
+// inKeymap returns the shortcuts that apply in keymap: those bound in it and
+// those bound in every keymap. An empty keymap keeps them all.
+func inKeymap(shortcuts []internal.Shortcut, keymap string) []internal.Shortcut {
+	if keymap == "" {
+		return shortcuts
+	}
+
+	var filtered []internal.Shortcut
+	for _, shortcut := range shortcuts {
+		if shortcut.Keymap == "" || shortcut.Keymap == keymap {
+			filtered = append(filtered, shortcut)
+		}
+	}
+	return filtered
+}
+
+func runList(args []string) int {
+	flags := flag.NewFlagSet("shortcutter list", flag.ContinueOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	keymap := flags.String("keymap", "", "only list shortcuts in `keymap`")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+
+	shortcuts, err := loadCommandShortcuts(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts: %v\n", err)
+		return 1
+	}
+
+	keyStyle := internal.LoadUIConfig().KeyStyle
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tKEYMAP\tDESCRIPTION")
+	for _, shortcut := range inKeymap(shortcuts, *keymap) {
+		key := shortcut.KeyLabel(keyStyle)
+		if shortcut.IsCustom {
+			key += " *"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key, shortcut.Type, shortcut.Keymap, shortcut.Description)
+	}
+	w.Flush()
+
+	return 0
+}
+
+func runShow(args []string) int {
+	flags := flag.NewFlagSet("shortcutter show", flag.ContinueOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	keymap := flags.String("keymap", "", "only show the shortcut in `keymap`")
+	flags.Usage = usage(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter show <key>")
+		return 2
+	}
+
+	shortcuts, err := loadCommandShortcuts(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts: %v\n", err)
+		return 1
+	}
+
+	matches := internal.FindShortcuts(shortcuts, positional[0], *keymap)
+	if len(matches) == 0 {
+		fmt.Fprintf(os.Stderr, "No shortcut found for '%s'\n", positional[0])
+		return 1
+	}
+
+	for i, shortcut := range matches {
+		if i > 0 {
+			fmt.Println()
+		}
+		printShortcut(shortcut)
+	}
+
+	return 0
+}
+
+func printShortcut(shortcut internal.Shortcut) {
+	custom := "no"
+	if shortcut.IsCustom {
+		custom = "yes"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Key:\t%s\n", shortcut.KeyLabel(internal.LoadUIConfig().KeyStyle))
+	fmt.Fprintf(w, "Description:\t%s\n", shortcut.Description)
+	fmt.Fprintf(w, "Type:\t%s\n", shortcut.Type)
+	fmt.Fprintf(w, "Target:\t%s\n", shortcut.Target)
+	fmt.Fprintf(w, "Keymap:\t%s\n", shortcut.Keymap)
+	fmt.Fprintf(w, "Category:\t%s\n", shortcut.Category)
+	fmt.Fprintf(w, "Source:\t%s\n", shortcut.Source)
+	fmt.Fprintf(w, "Custom:\t%s\n", custom)
+	w.Flush()
+}
+
+func runAdd(args []string) int {
+	flags := flag.NewFlagSet("shortcutter add", flag.ContinueOnError)
+	description := flags.String("description", "", "describe the shortcut as `text`")
+	shortcutType := flags.String("type", "", "shortcut `type` (widget, command, sequence or insert)")
+	target := flags.String("target", "", "widget name, command or key sequence to run, or text to insert")
+	keymap := flags.String("keymap", "", "bind the shortcut in `keymap` only")
+	category := flags.String("category", "", "group the shortcut under `category` in the picker")
+	flags.Usage = usage(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter add <key> [--description text] [--type type] [--target target] [--keymap keymap] [--category category]")
+		return 2
+	}
+	if *description == "" && *target == "" {
+		fmt.Fprintln(os.Stderr, "Error: add needs a --description or a --target")
+		return 2
+	}
+	if *target != "" && *shortcutType == "" {
+		*shortcutType = "command"
+	}
+
+	entry := internal.ConfigEntry{
+		Key:         positional[0],
+		Description: *description,
+		Type:        *shortcutType,
+		Target:      *target,
+		Keymap:      *keymap,
+		Category:    *category,
+	}
+	replaced, err := internal.AddConfigShortcut(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating config: %v\n", err)
+		return 1
+	}
+
+	if replaced {
+		fmt.Printf("Updated '%s'\n", entry.Key)
+	} else {
+		fmt.Printf("Added '%s'\n", entry.Key)
+	}
+	return 0
+}
+
+func runRemove(args []string) int {
+	flags := flag.NewFlagSet("shortcutter remove", flag.ContinueOnError)
+	keymap := flags.String("keymap", "", "remove the entry for `keymap` only")
+	flags.Usage = usage(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter remove <key> [--keymap keymap]")
+		return 2
+	}
+
+	removed, err := internal.RemoveConfigShortcut(positional[0], *keymap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating config: %v\n", err)
+		return 1
+	}
+	if removed == 0 {
+		fmt.Fprintf(os.Stderr, "No config entry found for '%s'\n", positional[0])
+		return 1
+	}
+
+	fmt.Printf("Removed '%s'\n", strings.TrimSpace(positional[0]))
+	return 0
+}
+
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter config validate [--json]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("shortcutter config validate", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print diagnostics as JSON")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args[1:]); err != nil {
+		return 2
+	}
+
+	path, err := internal.ConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating config: %v\n", err)
+		return 1
+	}
+
+	diagnostics, err := internal.ValidateConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		if diagnostics == nil {
+			diagnostics = []internal.Diagnostic{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(diagnostics)
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Println(internal.FormatDiagnostic(path, diagnostic))
+		}
+		if len(diagnostics) == 0 {
+			fmt.Printf("%s: no problems found\n", path)
+		}
+	}
+
+	if internal.HasErrors(diagnostics) {
+		return 1
+	}
+	return 0
+}
+
+func runStats(args []string) int {
+	flags := flag.NewFlagSet("shortcutter stats", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	top := flags.Int("top", 10, "list the `n` most picked shortcuts")
+	weeks := flags.Int("weeks", 8, "count selections over the last `n` weeks")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+
+	stats, err := internal.UsageReport(*top, *weeks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading usage log: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(stats)
+		return 0
+	}
+
+	printStats(stats)
+	return 0
+}
+
+// printStats writes the usage report as tables.
+func printStats(stats internal.UsageStats) {
+	if stats.Total == 0 {
+		fmt.Println("No selections recorded yet.")
+	} else {
+		fmt.Printf("%d selections from %s to %s\n", stats.Total, stats.First.Format("2006-01-02"), stats.Last.Format("2006-01-02"))
+
+		fmt.Println("\nTop shortcuts:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COUNT\tKEY\tTYPE\tTARGET\tLAST USED")
+		for _, usage := range stats.Top {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", usage.Count, usage.Key, usage.Type, usage.Target, usage.LastUsed.Format("2006-01-02"))
+		}
+		w.Flush()
+	}
+
+	if len(stats.Weeks) > 0 {
+		// Scale the bars down when a busy week would overflow the line
+		busiest := 40
+		for _, week := range stats.Weeks {
+			if week.Count > busiest {
+				busiest = week.Count
+			}
+		}
+
+		fmt.Println("\nSelections per week:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, week := range stats.Weeks {
+			bar := strings.Repeat("█", week.Count*40/busiest)
+			fmt.Fprintf(w, "%s\t%d\t%s\n", week.Start.Format("2006-01-02"), week.Count, bar)
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nBuilt-in shortcuts never used (%d):\n", len(stats.Unused))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, shortcut := range stats.Unused {
+		fmt.Fprintf(w, "%s\t%s\n", shortcut.Key, shortcut.Description)
+	}
+	w.Flush()
+}
+
+func runLearn(args []string) int {
+	flags := flag.NewFlagSet("shortcutter learn", flag.ContinueOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	keymap := flags.String("keymap", "emacs", "quiz bindings in `keymap`, or in all keymaps if empty")
+	newCards := flags.Int("new", 10, "introduce at most `n` shortcuts not seen before")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+
+	state, err := readShellState(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading shell state: %v\n", err)
+		return 1
+	}
+	shortcuts, styles, err := internal.LoadShortcutsAndThemeFromShell(state)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts and theme: %v\n", err)
+		return 1
+	}
+
+	progress, err := internal.LoadLearnProgress()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading learn progress: %v\n", err)
+		return 1
+	}
+
+	cards := internal.LearnSession(shortcuts, progress, *keymap, *newCards)
+	if len(cards) == 0 {
+		fmt.Println("Nothing to review right now.")
+		return 0
+	}
+
+	summary, err := internal.ShowLearn(cards, progress, styles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error showing learn mode: %v\n", err)
+		return 1
+	}
+	if err := progress.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving learn progress: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Reviewed %d, %d right first time", summary.Reviewed, summary.Correct)
+	if summary.Left > 0 {
+		fmt.Printf(", %d left for next time", summary.Left)
+	}
+	fmt.Println()
+	return 0
+}
+
+func runTip(args []string) int {
+	flags := flag.NewFlagSet("shortcutter tip", flag.ContinueOnError)
+	keymap := flags.String("keymap", "emacs", "suggest bindings in `keymap`")
+	flags.Usage = usage(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter tip [--keymap keymap] [buffer]")
+		return 2
+	}
+	buffer := ""
+	if len(positional) == 1 {
+		buffer = positional[0]
+	}
+
+	// The built-in catalog is used rather than live bindings so the tip
+	// prints without the shell having to dump its state first
+	shortcuts, styles, err := internal.LoadShortcutsAndTheme()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts and theme: %v\n", err)
+		return 1
+	}
+	// A broken usage file still gives a tip, just without favouring unused
+	// shortcuts, so say so rather than fail
+	history, err := internal.LoadUsage()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring usage history: %v\n", err)
+	}
+
+	tip, ok := internal.Tip(shortcuts, history, buffer, *keymap)
+	if !ok {
+		return 1
+	}
+	fmt.Println(internal.FormatTip(tip, styles, internal.LoadUIConfig().KeyStyle))
+	return 0
+}
+
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("shortcutter export", flag.ContinueOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	format := flags.String("format", "md", "document `format`: md, html or man")
+	keymap := flags.String("keymap", "", "only export shortcuts in `keymap`")
+	output := flags.String("output", "", "write the document to `file` instead of stdout")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+	if !internal.IsExportFormat(*format) {
+		fmt.Fprintf(os.Stderr, "Error: unknown format '%s' - use md, html or man\n", *format)
+		return 2
+	}
+
+	shortcuts, err := loadCommandShortcuts(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts: %v\n", err)
+		return 1
+	}
+	shortcuts = inKeymap(shortcuts, *keymap)
+
+	w := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *output, err)
+			return 1
+		}
+		w = file
+	}
+
+	theme := internal.LoadConfiguredTheme()
+	err = internal.ExportShortcuts(w, shortcuts, *format, theme, internal.LoadUIConfig().KeyStyle)
+	if w != os.Stdout {
+		// Some filesystems only report a failed write on close
+		if closeErr := w.Close(); err == nil && closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, closeErr)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting shortcuts: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runSheet(args []string) int {
+	flags := flag.NewFlagSet("shortcutter sheet", flag.ContinueOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	keymap := flags.String("keymap", "", "show shortcuts in `keymap` (default emacs when bound, as in the picker)")
+	width := flags.Int("width", 0, "lay out the sheet in `columns` instead of the terminal width")
+	flags.Usage = usage(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+
+	shortcuts, err := loadCommandShortcuts(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading shortcuts: %v\n", err)
+		return 1
+	}
+	// Like the picker, show one keymap rather than every catalog at once
+	shortcuts = inKeymap(shortcuts, internal.ActiveKeymap(shortcuts, *keymap))
+
+	if *width <= 0 {
+		*width = terminalWidth()
+	}
+	styles := internal.CreateThemeStyles(internal.LoadConfiguredTheme())
+	fmt.Println(internal.RenderSheet(shortcuts, styles, internal.LoadUIConfig().KeyStyle, *width))
+	return 0
+}
+
+// terminalWidth returns the width of the terminal stdout writes to, or of
+// $COLUMNS when stdout is not a terminal, and 0 when neither is known.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+		return width
+	}
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return width
+}
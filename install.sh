@@ -47,18 +47,30 @@ check_go() {
     print_success "Go is installed: $(go version)"
 }
 
-# Check if zsh is the current shell
-check_zsh() {
-    if [[ "$SHELL" != *"zsh"* ]]; then
-        print_warning "Current shell is not zsh. This tool is designed for zsh."
+# Check which supported shell is the current shell
+check_shell() {
+    if [[ "$SHELL" == *"zsh"* ]]; then
+        SHELL_NAME="zsh"
+        SHELL_RC="$HOME/.zshrc"
+    elif [[ "$SHELL" == *"bash"* ]]; then
+        SHELL_NAME="bash"
+        SHELL_RC="$HOME/.bashrc"
+    elif [[ "$SHELL" == *"fish"* ]]; then
+        SHELL_NAME="fish"
+        SHELL_RC="$HOME/.config/fish/config.fish"
+        mkdir -p "$(dirname "$SHELL_RC")"
+    else
+        print_warning "Current shell is not zsh, bash or fish."
         print_status "Current shell: $SHELL"
-        read -p "Continue anyway? (y/N) " -n 1 -r
+        read -p "Install the zsh integration anyway? (y/N) " -n 1 -r
         echo
         if [[ ! $REPLY =~ ^[Yy]$ ]]; then
             exit 1
         fi
+        SHELL_NAME="zsh"
+        SHELL_RC="$HOME/.zshrc"
     fi
-    print_success "zsh detected"
+    print_success "$SHELL_NAME detected"
 }
 
 # Build the binary
@@ -89,44 +101,52 @@ install_binary() {
     # Check if ~/.local/bin is in PATH
     if [[ ":$PATH:" != *":$bin_dir:"* ]]; then
         print_warning "~/.local/bin is not in your PATH"
-        print_status "Adding export to your .zshrc"
-        echo 'export PATH="$HOME/.local/bin:$PATH"' >> "$HOME/.zshrc"
-        print_status "Please restart your shell or run: source ~/.zshrc"
+        print_status "Adding export to $SHELL_RC"
+        if [[ "$SHELL_NAME" == "fish" ]]; then
+            echo 'fish_add_path $HOME/.local/bin' >> "$SHELL_RC"
+        else
+            echo 'export PATH="$HOME/.local/bin:$PATH"' >> "$SHELL_RC"
+        fi
+        print_status "Please restart your shell or run: source $SHELL_RC"
     fi
     
     print_success "Binary installed to $bin_dir/shortcutter"
 }
 
-# Add zsh integration
-install_zsh_integration() {
-    print_status "Installing zsh integration..."
+# Add shell integration
+install_shell_integration() {
+    print_status "Installing $SHELL_NAME integration..."
     
-    local zshrc="$HOME/.zshrc"
+    local rc_file="$SHELL_RC"
     local integration_marker="# Shortcutter integration"
     
     # Check if already installed
-    if grep -q "$integration_marker" "$zshrc" 2>/dev/null; then
-        print_warning "Shortcutter integration already exists in .zshrc"
+    if grep -q "$integration_marker" "$rc_file" 2>/dev/null; then
+        print_warning "Shortcutter integration already exists in $rc_file"
         read -p "Replace existing integration? (y/N) " -n 1 -r
         echo
         if [[ $REPLY =~ ^[Yy]$ ]]; then
             # Remove existing integration
-            sed -i.bak "/$integration_marker/,/# End shortcutter integration/d" "$zshrc"
+            sed -i.bak "/$integration_marker/,/# End shortcutter integration/d" "$rc_file"
             print_status "Removed existing integration"
         else
-            print_status "Skipping zsh integration"
+            print_status "Skipping $SHELL_NAME integration"
             return
         fi
     fi
     
-    # Add integration
-    echo "" >> "$zshrc"
-    echo "$integration_marker" >> "$zshrc"
-    cat shortcutter.zsh >> "$zshrc"
-    echo "" >> "$zshrc"
-    echo "# End shortcutter integration" >> "$zshrc"
+    # Add integration; the script itself is embedded in the binary, so
+    # upgrading the binary upgrades the integration too
+    echo "" >> "$rc_file"
+    echo "$integration_marker" >> "$rc_file"
+    if [[ "$SHELL_NAME" == "fish" ]]; then
+        echo "shortcutter init fish | source" >> "$rc_file"
+    else
+        echo "eval \"\$(shortcutter init $SHELL_NAME)\"" >> "$rc_file"
+    fi
+    echo "# End shortcutter integration" >> "$rc_file"
     
-    print_success "zsh integration added to .zshrc"
+    print_success "$SHELL_NAME integration added to $rc_file"
     print_status "Key binding: Ctrl+/ - Open shortcutter"
 }
 
@@ -137,16 +157,16 @@ main() {
     
     # Check system requirements
     check_go
-    check_zsh
+    check_shell
     
     # Build and install
     build_binary
     install_binary
-    install_zsh_integration
+    install_shell_integration
     
     echo
     print_success "🎉 Shortcutter installed successfully!"
-    print_status "Please restart your shell or run: source ~/.zshrc"
+    print_status "Please restart your shell or run: source $SHELL_RC"
     print_status "Then press Ctrl+/ anywhere in your shell to open shortcutter"
     echo
 }
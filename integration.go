@@ -0,0 +1,72 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"shortcutter/internal"
+	"text/template"
+)
+
+// The shell integration scripts are templates filled in by `shortcutter init`.
+//
+//go:embed shortcutter.zsh shortcutter.bash shortcutter.fish
+var integrationScripts embed.FS
+
+var widgetNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// integrationOptions fills in the integration script templates.
+type integrationOptions struct {
+	Key    string // Trigger key in the shell's own notation
+	Widget string // Name of the widget or function the trigger key runs
+}
+
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("shortcutter init", flag.ContinueOnError)
+	key := flags.String("key", internal.DefaultTriggerKey, "bind the picker to `key` (e.g. ^_, Ctrl+X Ctrl+S, M-/)")
+	widget := flags.String("widget", "shortcutter_widget", "`name` of the widget the key runs")
+	flags.Usage = usage(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: shortcutter init <zsh|bash|fish> [--key key] [--widget name]")
+		return 2
+	}
+	shell := positional[0]
+
+	if !widgetNamePattern.MatchString(*widget) {
+		fmt.Fprintf(os.Stderr, "Error: invalid widget name '%s'\n", *widget)
+		return 2
+	}
+
+	triggerKey, err := internal.TriggerKey(shell, *key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	script, err := template.ParseFS(integrationScripts, "shortcutter."+shell)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s integration: %v\n", shell, err)
+		return 1
+	}
+	if err := script.Execute(os.Stdout, integrationOptions{Key: triggerKey, Widget: *widget}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s integration: %v\n", shell, err)
+		return 1
+	}
+
+	if shell == "zsh" {
+		bindings, err := internal.GenerateZshBindings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating bindings: %v\n", err)
+			return 1
+		}
+		fmt.Print("\n" + bindings)
+	}
+
+	return 0
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseAliasOutput converts a dump of `alias -L` into shortcuts. Regular
+// aliases become commands. Global (-g) and suffix (-s) aliases only mean
+// something as part of a command line, so they become insert shortcuts that
+// put their text at the cursor instead of running it. Bash `alias -p` and
+// fish `alias` output are understood as well.
+func parseAliasOutput(output string) []Shortcut {
+	var shortcuts []Shortcut
+	for _, line := range strings.Split(output, "\n") {
+		name, expansion, kind, ok := parseAliasLine(line)
+		if !ok {
+			continue
+		}
+		shortcut := Shortcut{
+			Display:     name,
+			Description: expansion,
+			Type:        "command",
+			Target:      expansion,
+			IsCustom:    false,
+			Category:    "aliases",
+			Source:      "alias",
+		}
+		switch kind {
+		case "g":
+			shortcut.Type = "insert"
+		case "s":
+			// A suffix alias opens files with that extension in its command
+			shortcut.Type = "insert"
+			shortcut.Target = expansion + " "
+			shortcut.Description = fmt.Sprintf("Open *.%s files with %s", name, expansion)
+		}
+		shortcuts = append(shortcuts, shortcut)
+	}
+	return shortcuts
+}
+
+// parseAliasLine parses a single `alias [-g|-s] [--] name=value` line, or a
+// fish `alias name 'value'` line. The leading `alias` keyword is optional so
+// plain `alias` output works too. kind is "g" for a global alias, "s" for a
+// suffix alias and empty otherwise.
+func parseAliasLine(line string) (name, expansion, kind string, ok bool) {
+	words := splitShellWords(strings.TrimSpace(line))
+	if len(words) == 0 {
+		return "", "", "", false
+	}
+
+	if words[0] == "alias" {
+		words = words[1:]
+	}
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") && !strings.Contains(words[0], "=") {
+		if words[0] == "--" {
+			words = words[1:]
+			break
+		}
+		switch words[0] {
+		case "-g":
+			kind = "g"
+		case "-s":
+			kind = "s"
+		}
+		words = words[1:]
+	}
+
+	if len(words) == 2 && !strings.Contains(words[0], "=") {
+		// fish prints the name and expansion as separate words
+		if words[0] == "" || words[1] == "" {
+			return "", "", "", false
+		}
+		return words[0], words[1], kind, true
+	}
+
+	if len(words) != 1 {
+		return "", "", "", false
+	}
+
+	name, expansion, found := strings.Cut(words[0], "=")
+	if !found || name == "" || expansion == "" {
+		return "", "", "", false
+	}
+
+	return name, expansion, kind, true
+}
+
+// splitShellWords splits a line into words using POSIX shell quoting rules,
+// also understanding zsh's $'...' strings. Quotes are removed from the result.
+func splitShellWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			inWord = true
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				word.WriteString(line[i+1:])
+				i = len(line)
+			} else {
+				word.WriteString(line[i+1 : i+1+end])
+				i += end + 1
+			}
+		case c == '$' && i+1 < len(line) && line[i+1] == '\'':
+			inWord = true
+			i += 2
+			for ; i < len(line) && line[i] != '\''; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					word.WriteString(unescapeDollarQuote(line[i]))
+					continue
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '"':
+			inWord = true
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '\\' && i+1 < len(line):
+			inWord = true
+			i++
+			word.WriteByte(line[i])
+		default:
+			inWord = true
+			word.WriteByte(c)
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words
+}
+
+func unescapeDollarQuote(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'e', 'E':
+		return "\x1b"
+	default:
+		return string(c)
+	}
+}
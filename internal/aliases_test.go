@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestParseAliasLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		name      string
+		expansion string
+		kind      string
+	}{
+		{`alias ll='ls -lh'`, "ll", "ls -lh", ""},
+		{`alias gs='git status'`, "gs", "git status", ""},
+		{`alias -g G='| grep'`, "G", "| grep", "g"},
+		{`alias -s txt=vim`, "txt", "vim", "s"},
+		{`alias -- -='cd -'`, "-", "cd -", ""},
+		{`alias say='echo '\''hi'\'''`, "say", "echo 'hi'", ""},
+		{`alias nl=$'echo\n'`, "nl", "echo\n", ""},
+		{`ll='ls -lh'`, "ll", "ls -lh", ""},
+	}
+
+	for _, test := range tests {
+		name, expansion, kind, ok := parseAliasLine(test.line)
+		if !ok {
+			t.Errorf("parseAliasLine(%q) failed to parse", test.line)
+			continue
+		}
+		if name != test.name {
+			t.Errorf("parseAliasLine(%q) name = %q, want %q", test.line, name, test.name)
+		}
+		if expansion != test.expansion {
+			t.Errorf("parseAliasLine(%q) expansion = %q, want %q", test.line, expansion, test.expansion)
+		}
+		if kind != test.kind {
+			t.Errorf("parseAliasLine(%q) kind = %q, want %q", test.line, kind, test.kind)
+		}
+	}
+
+	for _, line := range []string{"", "alias", "alias ll", "alias =foo"} {
+		if _, _, _, ok := parseAliasLine(line); ok {
+			t.Errorf("parseAliasLine(%q) should not parse", line)
+		}
+	}
+}
+
+func TestParseAliasOutput(t *testing.T) {
+	output := `alias gs='git status'
+alias -g G='| grep'
+alias -s md=glow
+`
+
+	shortcuts := parseAliasOutput(output)
+	if len(shortcuts) != 3 {
+		t.Fatalf("parseAliasOutput() returned %d shortcuts, want 3", len(shortcuts))
+	}
+
+	tests := []struct {
+		display      string
+		shortcutType string
+		target       string
+		description  string
+	}{
+		{"gs", "command", "git status", "git status"},
+		// Running `| grep` on its own is a syntax error, so it is inserted
+		{"G", "insert", "| grep", "| grep"},
+		// Running bare `glow` would lose the file, so it is inserted as well
+		{"md", "insert", "glow ", "Open *.md files with glow"},
+	}
+	for i, test := range tests {
+		shortcut := shortcuts[i]
+		if shortcut.Display != test.display || shortcut.Type != test.shortcutType || shortcut.Target != test.target {
+			t.Errorf("Alias %d = %q %s %q, want %q %s %q", i, shortcut.Display, shortcut.Type, shortcut.Target,
+				test.display, test.shortcutType, test.target)
+		}
+		if shortcut.Description != test.description {
+			t.Errorf("Alias %q description = %q, want %q", shortcut.Display, shortcut.Description, test.description)
+		}
+	}
+}
+
+func TestAliasesMergeWithConfig(t *testing.T) {
+	shortcuts, err := getShellShortcuts("zsh", ShellState{Aliases: "alias gs='git status'\nalias gp='git push'"})
+	if err != nil {
+		t.Fatalf("getShellShortcuts() returned error: %v", err)
+	}
+
+	config := &Config{
+		Shortcuts: map[string]interface{}{
+			"gp": false,
+			"gs": "Show working tree status",
+		},
+	}
+	result := mergeShortcuts(shortcuts, config)
+
+	foundGs := false
+	for _, shortcut := range result {
+		if shortcut.Display == "gp" {
+			t.Error("Alias gp should have been disabled by config")
+		}
+		if shortcut.Display == "gs" {
+			foundGs = true
+			if shortcut.Description != "Show working tree status" {
+				t.Errorf("Alias gs description = %q, want %q", shortcut.Description, "Show working tree status")
+			}
+			if shortcut.Target != "git status" {
+				t.Errorf("Alias gs target = %q, want %q", shortcut.Target, "git status")
+			}
+		}
+	}
+	if !foundGs {
+		t.Error("Alias gs not found after merge")
+	}
+}
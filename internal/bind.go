@@ -0,0 +1,275 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bindEntry is a single binding parsed from bash `bind -p` or `bind -X` output.
+type bindEntry struct {
+	Keys     string // Raw key sequence (decoded bytes)
+	Function string // Readline function, empty for `bind -X` commands
+	Command  string // Shell command bound with `bind -x`
+}
+
+// parseBindOutput converts a dump of `bind -p` and `bind -X` into shortcuts.
+// Readline functions become widgets and `bind -x` commands become commands.
+// Comments, unbound functions and self-insert keys are skipped.
+func parseBindOutput(output string) []Shortcut {
+	var shortcuts []Shortcut
+	for _, line := range strings.Split(output, "\n") {
+		entry, ok := parseBindLine(line)
+		if !ok {
+			continue
+		}
+		switch entry.Function {
+		case "self-insert", "do-lowercase-version":
+			continue
+		}
+		shortcuts = append(shortcuts, entry.shortcut())
+	}
+	return shortcuts
+}
+
+func parseBindLine(line string) (bindEntry, bool) {
+	var entry bindEntry
+
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, `"`) {
+		return entry, false
+	}
+
+	keys, next, err := readReadlineQuoted(line, 0)
+	if err != nil || keys == "" {
+		return entry, false
+	}
+
+	rest := strings.TrimSpace(line[next:])
+	if !strings.HasPrefix(rest, ":") {
+		return entry, false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if rest == "" {
+		return entry, false
+	}
+
+	entry.Keys = keys
+	if strings.HasPrefix(rest, `"`) {
+		command, _, err := readReadlineQuoted(rest, 0)
+		if err != nil {
+			return entry, false
+		}
+		entry.Command = command
+	} else {
+		entry.Function = rest
+	}
+
+	return entry, true
+}
+
+func (e bindEntry) shortcut() Shortcut {
+	keys := keySequenceFromBytes(e.Keys)
+
+	if e.Function == "" {
+		return Shortcut{
+			Display:     keys.Display(),
+			Keys:        keys,
+			Description: e.Command,
+			Type:        "command",
+			Target:      e.Command,
+			IsCustom:    false,
+		}
+	}
+
+	return Shortcut{
+		Display:     keys.Display(),
+		Keys:        keys,
+		Description: describeReadlineFunction(e.Function),
+		Type:        "widget",
+		Target:      e.Function,
+		IsCustom:    false,
+	}
+}
+
+// readReadlineQuoted decodes the double-quoted inputrc string starting at
+// line[start] and returns its raw bytes along with the index just past the
+// closing quote.
+func readReadlineQuoted(line string, start int) (string, int, error) {
+	var b strings.Builder
+
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		if c == '"' {
+			return b.String(), i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(line) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch line[i] {
+		case 'C':
+			if i+2 < len(line) && line[i+1] == '-' {
+				i += 2
+				key := line[i]
+				if key == '\\' && i+1 < len(line) {
+					i++
+					key = line[i]
+				}
+				if key == '?' {
+					b.WriteByte(0x7f)
+				} else {
+					b.WriteByte(key & 0x1f)
+				}
+				continue
+			}
+			b.WriteByte('C')
+		case 'M':
+			if i+1 < len(line) && line[i+1] == '-' {
+				i++
+				b.WriteByte(0x1b)
+				continue
+			}
+			b.WriteByte('M')
+		case 'e':
+			b.WriteByte(0x1b)
+		case 'a':
+			b.WriteByte(0x07)
+		case 'b':
+			b.WriteByte(0x08)
+		case 'd':
+			b.WriteByte(0x7f)
+		case 'f':
+			b.WriteByte(0x0c)
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte(0x0b)
+		case 'x':
+			end := i + 1
+			for end < len(line) && end < i+3 && isHexDigit(line[end]) {
+				end++
+			}
+			if value, err := strconv.ParseUint(line[i+1:end], 16, 8); err == nil {
+				b.WriteByte(byte(value))
+				i = end - 1
+			} else {
+				b.WriteByte('x')
+			}
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := i
+			for end < len(line) && end < i+3 && line[end] >= '0' && line[end] <= '7' {
+				end++
+			}
+			value, _ := strconv.ParseUint(line[i:end], 8, 8)
+			b.WriteByte(byte(value))
+			i = end - 1
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string in %q", line)
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// describeReadlineFunction returns a human-readable description for a
+// readline function, falling back to a title-cased version of its name.
+func describeReadlineFunction(function string) string {
+	if description, ok := readlineFunctionDescriptions[function]; ok {
+		return description
+	}
+	return humanizeWidgetName(function)
+}
+
+var readlineFunctionDescriptions = map[string]string{
+	"abort":                    "Abort current operation",
+	"accept-line":              "Execute command",
+	"backward-char":            "Back one character",
+	"backward-delete-char":     "Kill one character backward",
+	"backward-kill-line":       "Kill to beginning of line",
+	"backward-kill-word":       "Kill word backward",
+	"backward-word":            "Back one word",
+	"beginning-of-history":     "First line in history",
+	"beginning-of-line":        "Beginning of the line",
+	"bracketed-paste-begin":    "Paste from terminal",
+	"capitalize-word":          "Capitalize word",
+	"character-search":         "Jump to next typed character",
+	"clear-screen":             "Clear screen",
+	"complete":                 "Complete command/filename",
+	"delete-char":              "Delete character or EOF",
+	"digit-argument":           "Start numeric argument",
+	"downcase-word":            "Lowercase word",
+	"edit-and-execute-command": "Edit command in editor",
+	"end-of-history":           "Last line in history",
+	"end-of-line":              "End of the line",
+	"exchange-point-and-mark":  "Swap cursor and Mark",
+	"forward-char":             "Forward one character",
+	"forward-search-history":   "Search forward",
+	"forward-word":             "Forward one word",
+	"history-expand-line":      "Expand history references",
+	"insert-comment":           "Comment out line and execute",
+	"insert-completions":       "Insert all completions",
+	"kill-line":                "Kill to end of line",
+	"kill-word":                "Kill word forward",
+	"next-history":             "Next Line",
+	"operate-and-get-next":     "Exec cmd and fetch next history line",
+	"possible-completions":     "List completions",
+	"previous-history":         "Prev line",
+	"quoted-insert":            "Quoted insert",
+	"redraw-current-line":      "Redraw line",
+	"reverse-search-history":   "Search",
+	"set-mark":                 "Set Mark",
+	"shell-expand-line":        "Expand aliases and history",
+	"transpose-chars":          "Swap cursor with prev character",
+	"transpose-words":          "Swap cursor with prev word",
+	"undo":                     "Undo",
+	"unix-line-discard":        "Clear to beginning of line",
+	"unix-word-rubout":         "Kill word back to whitespace",
+	"upcase-word":              "Uppercase word",
+	"yank":                     "Paste from Kill Ring",
+	"yank-last-arg":            "Extract last word",
+	"yank-pop":                 "Cycle Kill Ring",
+}
+
+// quoteReadlineString renders raw key bytes as a double-quoted readline key
+// sequence, e.g. "\C-x\C-e". Single quotes are written in octal so the
+// result can be embedded in a single-quoted `bind` argument.
+func quoteReadlineString(s string) string {
+	return `"` + strings.ReplaceAll(readlineNotation(s), "'", `\047`) + `"`
+}
+
+// readlineNotation renders raw key bytes in inputrc notation, escaping the
+// characters that are special inside its double quotes.
+func readlineNotation(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x1b:
+			b.WriteString(`\e`)
+		case c == 0x7f:
+			b.WriteString(`\C-?`)
+		case c < 0x20:
+			b.WriteString(`\C-`)
+			b.WriteString(strings.ToLower(string(c + 0x40)))
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x80:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
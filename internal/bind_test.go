@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestParseBindLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		display string
+		typ     string
+		target  string
+	}{
+		{`"\C-a": beginning-of-line`, "Ctrl+A", "widget", "beginning-of-line"},
+		{`"\C-x\C-e": edit-and-execute-command`, "Ctrl+X Ctrl+E", "widget", "edit-and-execute-command"},
+		{`"\ef": forward-word`, "Alt+F", "widget", "forward-word"},
+		{`"\M-b": backward-word`, "Alt+B", "widget", "backward-word"},
+		{`"\e\C-e": shell-expand-line`, "Ctrl+Alt+E", "widget", "shell-expand-line"},
+		{`"\e\C-?": backward-kill-word`, "Alt+Backspace", "widget", "backward-kill-word"},
+		{`"\C-?": backward-delete-char`, "Backspace", "widget", "backward-delete-char"},
+		{`"\e[A": previous-history`, "↑", "widget", "previous-history"},
+		{`"\e[3~": delete-char`, "Delete", "widget", "delete-char"},
+		{`"\C-\\": abort`, `Ctrl+\`, "widget", "abort"},
+		{`"\e\"": insert-comment`, `Alt+"`, "widget", "insert-comment"},
+		{`"\033f": forward-word`, "Alt+F", "widget", "forward-word"},
+		{`"\x1bb": backward-word`, "Alt+B", "widget", "backward-word"},
+		{`"\C-xg": "git status"`, "Ctrl+X g", "command", "git status"},
+	}
+
+	for _, test := range tests {
+		entry, ok := parseBindLine(test.line)
+		if !ok {
+			t.Errorf("parseBindLine(%q) failed to parse", test.line)
+			continue
+		}
+		shortcut := entry.shortcut()
+		if shortcut.Display != test.display {
+			t.Errorf("parseBindLine(%q) display = %q, want %q", test.line, shortcut.Display, test.display)
+		}
+		if shortcut.Type != test.typ {
+			t.Errorf("parseBindLine(%q) type = %q, want %q", test.line, shortcut.Type, test.typ)
+		}
+		if shortcut.Target != test.target {
+			t.Errorf("parseBindLine(%q) target = %q, want %q", test.line, shortcut.Target, test.target)
+		}
+		if shortcut.Description == "" {
+			t.Errorf("parseBindLine(%q) has empty description", test.line)
+		}
+	}
+}
+
+func TestParseBindOutput(t *testing.T) {
+	output := `# abort (not bound)
+"\C-a": beginning-of-line
+"a": self-insert
+"b": self-insert
+# alias-expand-line (not bound)
+"\C-x\C-e": edit-and-execute-command
+"\C-xg": "git status"
+`
+
+	shortcuts := parseBindOutput(output)
+	if len(shortcuts) != 3 {
+		t.Fatalf("parseBindOutput() returned %d shortcuts, want 3", len(shortcuts))
+	}
+
+	if shortcuts[1].Description != "Edit command in editor" {
+		t.Errorf("edit-and-execute-command description = %q, want %q", shortcuts[1].Description, "Edit command in editor")
+	}
+}
+
+func TestLoadShortcutsFromShellBash(t *testing.T) {
+	originalGetShellEnv := getShellEnv
+	defer func() { getShellEnv = originalGetShellEnv }()
+
+	getShellEnv = func() string { return "/bin/bash" }
+
+	shortcuts, err := LoadShortcutsFromShell(ShellState{
+		Bindings:  `"\C-r": reverse-search-history`,
+		Aliases:   `alias ll='ls -l'`,
+		Functions: "mkcd () \n{ \n    mkdir -p \"$1\" && cd \"$1\"\n}\n",
+	})
+	if err != nil {
+		t.Fatalf("LoadShortcutsFromShell() returned error: %v", err)
+	}
+
+	if len(shortcuts) != 3 {
+		t.Errorf("LoadShortcutsFromShell() returned %d shortcuts, want 3", len(shortcuts))
+	}
+}
@@ -0,0 +1,365 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bindkeyEntry is a single binding parsed from `bindkey` or `bindkey -L` output.
+type bindkeyEntry struct {
+	Keymap  string // Keymap given with -M, empty for the main keymap
+	Keys    string // Raw key sequence (decoded bytes)
+	RangeTo string // End of the range for `"a"-"b"` bindings, empty otherwise
+	Widget  string // Bound widget, empty for -s string bindings
+	String  string // Output string for -s bindings
+}
+
+// parseBindkeyOutput converts a dump of `bindkey` or `bindkey -L` into shortcuts.
+// Lines it does not understand are skipped.
+func parseBindkeyOutput(output string) []Shortcut {
+	var shortcuts []Shortcut
+	for _, line := range strings.Split(output, "\n") {
+		entry, ok := parseBindkeyLine(line)
+		if !ok {
+			continue
+		}
+		if entry.Widget == "undefined-key" {
+			continue
+		}
+		shortcuts = append(shortcuts, entry.shortcut())
+	}
+	return shortcuts
+}
+
+func parseBindkeyLine(line string) (bindkeyEntry, bool) {
+	var entry bindkeyEntry
+
+	tokens, err := tokenizeBindkeyLine(strings.TrimSpace(line))
+	if err != nil || len(tokens) == 0 {
+		return entry, false
+	}
+
+	isString := false
+	if tokens[0].text == "bindkey" && !tokens[0].quoted {
+		tokens = tokens[1:]
+		for len(tokens) > 0 && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "-") {
+			switch tokens[0].text {
+			case "-M":
+				if len(tokens) < 2 {
+					return entry, false
+				}
+				entry.Keymap = tokens[1].text
+				tokens = tokens[1:]
+			case "-a":
+				// `bindkey -L -M vicmd` writes the vicmd keymap as -a
+				entry.Keymap = "vicmd"
+			case "-s":
+				isString = true
+			case "-R":
+				// Ranges are detected from the key token itself
+			}
+			tokens = tokens[1:]
+		}
+	}
+
+	if len(tokens) != 2 || !tokens[0].quoted {
+		return entry, false
+	}
+
+	entry.Keys = tokens[0].text
+	entry.RangeTo = tokens[0].rangeTo
+	if tokens[1].quoted || isString {
+		entry.String = tokens[1].text
+	} else {
+		entry.Widget = tokens[1].text
+	}
+
+	if entry.Keys == "" {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+func (e bindkeyEntry) shortcut() Shortcut {
+	keys := keySequenceFromBytes(e.Keys)
+	display := keys.Display()
+	if e.RangeTo != "" {
+		// A range binds every key in between, not one sequence
+		display = display + ".." + keySequenceFromBytes(e.RangeTo).Display()
+		keys = nil
+	}
+
+	if e.Widget == "" {
+		return Shortcut{
+			Display:     display,
+			Keys:        keys,
+			Description: "Type " + quoteBindkeyString(e.String),
+			Type:        "sequence",
+			Target:      keySequenceFromBytes(e.String).Emacs(),
+			Keymap:      e.Keymap,
+			IsCustom:    false,
+		}
+	}
+
+	return Shortcut{
+		Display:     display,
+		Keys:        keys,
+		Description: describeZshWidget(e.Widget),
+		Type:        "widget",
+		Target:      e.Widget,
+		Keymap:      e.Keymap,
+		IsCustom:    false,
+	}
+}
+
+// WidgetKeys finds the shortest key sequence bound to widget in keymap in a
+// dump of `bindkey -L`, so the integration can replay it with `zle -U` and
+// the widget runs with the same ZLE context as when typed. Lines without -M
+// or -a are assumed to belong to keymap.
+func WidgetKeys(bindings string, widget string, keymap string) (string, bool) {
+	best := ""
+	bestExact := false
+	for _, line := range strings.Split(bindings, "\n") {
+		entry, ok := parseBindkeyLine(line)
+		if !ok || entry.Widget != widget || entry.RangeTo != "" {
+			continue
+		}
+		exact := entry.Keymap == keymap
+		if !exact && entry.Keymap != "" {
+			continue
+		}
+		if best == "" || (exact && !bestExact) || (exact == bestExact && len(entry.Keys) < len(best)) {
+			best, bestExact = entry.Keys, exact
+		}
+	}
+	return best, best != ""
+}
+
+type bindkeyToken struct {
+	text    string
+	rangeTo string
+	quoted  bool
+}
+
+func tokenizeBindkeyLine(line string) ([]bindkeyToken, error) {
+	var tokens []bindkeyToken
+
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			text, next, err := readBindkeyQuoted(line, i)
+			if err != nil {
+				return nil, err
+			}
+			token := bindkeyToken{text: text, quoted: true}
+			if next+1 < len(line) && line[next] == '-' && line[next+1] == '"' {
+				rangeTo, after, err := readBindkeyQuoted(line, next+1)
+				if err != nil {
+					return nil, err
+				}
+				token.rangeTo = rangeTo
+				next = after
+			}
+			tokens = append(tokens, token)
+			i = next
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			tokens = append(tokens, bindkeyToken{text: line[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// readBindkeyQuoted decodes the double-quoted string starting at line[start]
+// and returns its raw bytes along with the index just past the closing quote.
+func readBindkeyQuoted(line string, start int) (string, int, error) {
+	var b strings.Builder
+	meta := false
+
+	emit := func(c byte) {
+		if meta {
+			c |= 0x80
+			meta = false
+		}
+		b.WriteByte(c)
+	}
+
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '"':
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(line):
+			i++
+			switch line[i] {
+			case 'M':
+				if i+1 < len(line) && line[i+1] == '-' {
+					meta = true
+					i++
+					continue
+				}
+				emit('M')
+			case 'e', 'E':
+				emit(0x1b)
+			case 'n':
+				emit('\n')
+			case 't':
+				emit('\t')
+			default:
+				emit(line[i])
+			}
+		case c == '^' && i+1 < len(line) && line[i+1] != '"':
+			i++
+			if line[i] == '?' {
+				emit(0x7f)
+			} else {
+				emit(line[i] & 0x1f)
+			}
+		default:
+			emit(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string in %q", line)
+}
+
+// quoteBindkeyString renders raw bytes back into bindkey's caret notation.
+func quoteBindkeyString(s string) string {
+	return `"` + bindkeyNotation(s) + `"`
+}
+
+// bindkeyNotation renders raw bytes in caret notation, escaping the
+// characters that are special inside bindkey's double quotes.
+func bindkeyNotation(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x80 {
+			b.WriteString(`\M-`)
+			c &= 0x7f
+		}
+		switch {
+		case c == 0x7f:
+			b.WriteString("^?")
+		case c < 0x20:
+			b.WriteByte('^')
+			b.WriteByte(c + 0x40)
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// describeZshWidget returns a human-readable description for a ZLE widget,
+// falling back to a title-cased version of the widget name.
+func describeZshWidget(widget string) string {
+	if description, ok := zshWidgetDescriptions[widget]; ok {
+		return description
+	}
+	return humanizeWidgetName(widget)
+}
+
+// humanizeWidgetName turns a widget name like "_expand_alias" into "Expand alias".
+func humanizeWidgetName(widget string) string {
+	name := strings.TrimLeft(widget, "_.")
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	if name == "" {
+		return widget
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+var zshWidgetDescriptions = map[string]string{
+	"accept-and-hold":                     "Execute command and keep line",
+	"accept-line":                         "Execute command",
+	"accept-line-and-down-history":        "Exec cmd but keep line",
+	"backward-char":                       "Back one character",
+	"backward-delete-char":                "Kill one character backward",
+	"backward-kill-line":                  "Clear to beginning of line",
+	"backward-kill-word":                  "Kill word back (if no Mark)",
+	"backward-word":                       "Back one word",
+	"beginning-of-buffer-or-history":      "Beginning of buffer or history",
+	"beginning-of-line":                   "Beginning of the line",
+	"bracketed-paste":                     "Paste from terminal",
+	"capitalize-word":                     "Capitalize word",
+	"clear-screen":                        "Clear screen",
+	"copy-prev-word":                      "Duplicate previous word",
+	"copy-region-as-kill":                 "Copy region to Kill Ring",
+	"delete-char":                         "Delete character under cursor",
+	"delete-char-or-list":                 "Delete character or EOF",
+	"digit-argument":                      "Start numeric argument",
+	"down-case-word":                      "Lowercase word",
+	"down-line-or-history":                "Next Line",
+	"edit-command-line":                   "Edit command in editor",
+	"end-of-buffer-or-history":            "End of buffer or history",
+	"end-of-line":                         "End of the line",
+	"exchange-point-and-mark":             "Swap cursor and Mark",
+	"execute-last-named-cmd":              "Repeat last named widget",
+	"execute-named-cmd":                   "Run widget by name",
+	"expand-history":                      "Expand history reference",
+	"expand-or-complete":                  "Complete command/filename",
+	"expand-or-complete-prefix":           "Complete word before cursor",
+	"expand-word":                         "Expand word",
+	"forward-char":                        "Forward one character",
+	"forward-word":                        "Forward one word",
+	"get-line":                            "Pop line from buffer stack",
+	"history-incremental-search-backward": "Search",
+	"history-incremental-search-forward":  "Search forward",
+	"history-search-backward":             "Match word on line",
+	"history-search-forward":              "Match word on line forward",
+	"infer-next-history":                  "Next history after match",
+	"insert-last-word":                    "Extract last word",
+	"kill-buffer":                         "Kill entire buffer",
+	"kill-line":                           "Kill to end of line",
+	"kill-word":                           "Kill word forward",
+	"list-choices":                        "List completions",
+	"list-expand":                         "List expansions",
+	"neg-argument":                        "Negate numeric argument",
+	"overwrite-mode":                      "Toggle overwrite mode",
+	"push-line":                           "Push line to be used again",
+	"quote-line":                          "Quote entire line",
+	"quote-region":                        "Quote region",
+	"quoted-insert":                       "Quoted insert",
+	"run-help":                            "Show help for command",
+	"self-insert":                         "Insert typed character",
+	"self-insert-unmeta":                  "Insert character without Meta",
+	"send-break":                          "Abort current operation",
+	"set-mark-command":                    "Set Mark",
+	"spell-word":                          "Spell check word",
+	"transpose-chars":                     "Swap cursor with prev character",
+	"transpose-words":                     "Swap cursor with prev word",
+	"undo":                                "Undo",
+	"up-case-word":                        "Uppercase word",
+	"up-line-or-history":                  "Prev line",
+	"vi-cmd-mode":                         "Switch to vi command mode",
+	"vi-find-next-char":                   "Jump to next typed character",
+	"vi-goto-column":                      "Go to column",
+	"vi-join":                             "Join with next line",
+	"vi-match-bracket":                    "Jump to matching bracket",
+	"what-cursor-position":                "Show cursor position",
+	"which-command":                       "Show what a command is",
+	"yank":                                "Paste from Kill Ring",
+	"yank-pop":                            "Cycle Kill Ring",
+}
+
+// keySequenceBytes turns a key as written in the config ("Ctrl+X g",
+// "C-x C-e", "M-f", "^X^E", "gg") or in emacs notation ("C-c", "M-<left>",
+// "<f5>", "sudo SPC") into the raw bytes the terminal sends.
+func keySequenceBytes(spec string) (string, error) {
+	sequence, err := ParseKeySequence(spec)
+	if err != nil {
+		return "", err
+	}
+	return sequence.Bytes(), nil
+}
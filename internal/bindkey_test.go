@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xo/terminfo"
+)
+
+func TestParseBindkeyLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		display string
+		typ     string
+		target  string
+	}{
+		{`"^A" beginning-of-line`, "Ctrl+A", "widget", "beginning-of-line"},
+		{`"^X^E" edit-command-line`, "Ctrl+X Ctrl+E", "widget", "edit-command-line"},
+		{`"^[f" forward-word`, "Alt+F", "widget", "forward-word"},
+		{`"^[F" forward-word`, "Alt+Shift+F", "widget", "forward-word"},
+		{`"^[^D" list-choices`, "Ctrl+Alt+D", "widget", "list-choices"},
+		{`"^[^?" backward-kill-word`, "Alt+Backspace", "widget", "backward-kill-word"},
+		{`"^[\"" quote-region`, `Alt+"`, "widget", "quote-region"},
+		{`"^[\$" spell-word`, "Alt+$", "widget", "spell-word"},
+		{`"^[[A" up-line-or-history`, "↑", "widget", "up-line-or-history"},
+		{`"^[OH" beginning-of-line`, "Home", "widget", "beginning-of-line"},
+		{`"^[[3~" delete-char`, "Delete", "widget", "delete-char"},
+		{`"^XG" list-expand`, "Ctrl+X G", "widget", "list-expand"},
+		{`"^Xg" list-expand`, "Ctrl+X g", "widget", "list-expand"},
+		{`"^?" backward-delete-char`, "Backspace", "widget", "backward-delete-char"},
+		{`" "-"~" self-insert`, "Space..~", "widget", "self-insert"},
+		{`"\M-^@"-"\M-^?" self-insert`, "Meta+Ctrl+@..Meta+Backspace", "widget", "self-insert"},
+		{`bindkey "^R" atuin-search`, "Ctrl+R", "widget", "atuin-search"},
+		{`bindkey -R " "-"~" self-insert`, "Space..~", "widget", "self-insert"},
+		{`bindkey -s "^Xg" "git status^M"`, "Ctrl+X g", "sequence", "git SPC status RET"},
+		{`"^Xs" "^Asudo ^E"`, "Ctrl+X s", "sequence", "C-a sudo SPC C-e"},
+	}
+
+	for _, test := range tests {
+		entry, ok := parseBindkeyLine(test.line)
+		if !ok {
+			t.Errorf("parseBindkeyLine(%q) failed to parse", test.line)
+			continue
+		}
+		shortcut := entry.shortcut()
+		if shortcut.Display != test.display {
+			t.Errorf("parseBindkeyLine(%q) display = %q, want %q", test.line, shortcut.Display, test.display)
+		}
+		if shortcut.Type != test.typ {
+			t.Errorf("parseBindkeyLine(%q) type = %q, want %q", test.line, shortcut.Type, test.typ)
+		}
+		if shortcut.Target != test.target {
+			t.Errorf("parseBindkeyLine(%q) target = %q, want %q", test.line, shortcut.Target, test.target)
+		}
+		if shortcut.Description == "" {
+			t.Errorf("parseBindkeyLine(%q) has empty description", test.line)
+		}
+	}
+}
+
+func TestParseBindkeyLineInvalid(t *testing.T) {
+	lines := []string{
+		"",
+		"edit-command-line",
+		`"^X^E`,
+		`bindkey -M`,
+		`"^A"`,
+	}
+
+	for _, line := range lines {
+		if _, ok := parseBindkeyLine(line); ok {
+			t.Errorf("parseBindkeyLine(%q) should not parse", line)
+		}
+	}
+}
+
+func TestParseBindkeyOutputFromDump(t *testing.T) {
+	data, err := os.ReadFile("../commands.txt")
+	if err != nil {
+		t.Skipf("commands.txt not available: %v", err)
+	}
+
+	shortcuts := parseBindkeyOutput(string(data))
+	if len(shortcuts) < 130 {
+		t.Errorf("parseBindkeyOutput() returned %d shortcuts, want at least 130", len(shortcuts))
+	}
+
+	found := false
+	for _, shortcut := range shortcuts {
+		if shortcut.Display == "Ctrl+R" {
+			found = true
+			if shortcut.Target != "atuin-search" {
+				t.Errorf("Ctrl+R target = %q, want %q", shortcut.Target, "atuin-search")
+			}
+		}
+		if shortcut.Description == "" {
+			t.Errorf("Shortcut %q has empty description", shortcut.Display)
+		}
+	}
+	if !found {
+		t.Error("Ctrl+R binding not found in dump")
+	}
+}
+
+func TestDescribeZshWidget(t *testing.T) {
+	tests := []struct {
+		widget   string
+		expected string
+	}{
+		{"edit-command-line", "Edit command in editor"},
+		{"atuin-search", "Atuin search"},
+		{"_expand_alias", "Expand alias"},
+	}
+
+	for _, test := range tests {
+		result := describeZshWidget(test.widget)
+		if result != test.expected {
+			t.Errorf("describeZshWidget(%q) = %q, want %q", test.widget, result, test.expected)
+		}
+	}
+}
+
+func TestLoadShortcutsFromShellBindkeys(t *testing.T) {
+	originalGetShellEnv := getShellEnv
+	defer func() { getShellEnv = originalGetShellEnv }()
+
+	getShellEnv = func() string { return "/bin/zsh" }
+
+	shortcuts, err := LoadShortcutsFromShell(ShellState{Bindings: `"^R" atuin-search
+"^X^E" edit-command-line`})
+	if err != nil {
+		t.Fatalf("LoadShortcutsFromShell() returned error: %v", err)
+	}
+
+	if len(shortcuts) != 2 {
+		t.Errorf("LoadShortcutsFromShell() returned %d shortcuts, want 2", len(shortcuts))
+	}
+}
+
+func TestParseBindkeyKeymaps(t *testing.T) {
+	output := `bindkey -a "gg" beginning-of-buffer-or-history
+bindkey -M viins "^[" vi-cmd-mode
+bindkey "^A" beginning-of-line`
+
+	shortcuts := parseBindkeyOutput(output)
+	if len(shortcuts) != 3 {
+		t.Fatalf("parseBindkeyOutput() returned %d shortcuts, want 3", len(shortcuts))
+	}
+
+	expected := []struct{ display, keymap string }{
+		{"gg", "vicmd"},
+		{"Esc", "viins"},
+		{"Ctrl+A", ""},
+	}
+	for i, want := range expected {
+		if shortcuts[i].Display != want.display || shortcuts[i].Keymap != want.keymap {
+			t.Errorf("Shortcut %d = %q in %q, want %q in %q", i, shortcuts[i].Display, shortcuts[i].Keymap, want.display, want.keymap)
+		}
+	}
+}
+
+// withXtermKeys makes terminal key lookups use the built-in xterm sequences.
+func withXtermKeys(t *testing.T) {
+	original := loadTerminfo
+	loadTerminfo = func() (*terminfo.Terminfo, error) {
+		return nil, terminfo.ErrFileNotFound
+	}
+	t.Cleanup(func() { loadTerminfo = original })
+}
+
+func TestKeySequenceBytes(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"Ctrl+X g", "\x18g"},
+		{"C-x C-e", "\x18\x05"},
+		{"ctrl+x ctrl+e", "\x18\x05"},
+		{"^X^E", "\x18\x05"},
+		{"M-f", "\x1bf"},
+		{"Alt+F", "\x1bf"},
+		{"Alt+Shift+F", "\x1bF"},
+		{"Ctrl+Alt+D", "\x1b\x04"},
+		{"C-M-d", "\x1b\x04"},
+		{"Alt+.", "\x1b."},
+		{"Ctrl+_", "\x1f"},
+		{"Ctrl+?", "\x7f"},
+		{"Shift+Tab", "\x1b[Z"},
+		{"Alt+Backspace", "\x1b\x7f"},
+		{"↑", "\x1b[A"},
+		{"Home", "\x1b[H"},
+		{"gg", "gg"},
+		{"C-a sudo Space", "\x01sudo "},
+		{"C-a sudo SPC RET", "\x01sudo \r"},
+		{"<f5>", "\x1b[15~"},
+		{"<up> <down>", "\x1b[A\x1b[B"},
+		{"M-<left>", "\x1b\x1b[D"},
+		{"C-<up>", "\x1b[1;5A"},
+		{"Ctrl+Home", "\x1b[1;5H"},
+		{"S-<f1>", "\x1b[1;2P"},
+		{"C-<delete>", "\x1b[3;5~"},
+		{"<prior>", "\x1b[5~"},
+	}
+
+	for _, test := range tests {
+		result, err := keySequenceBytes(test.spec)
+		if err != nil {
+			t.Errorf("keySequenceBytes(%q) returned error: %v", test.spec, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("keySequenceBytes(%q) = %q, want %q", test.spec, result, test.expected)
+		}
+	}
+
+	for _, spec := range []string{"", "Hyper+X", "Ctrl+Foo", "Ctrl+1", "<f99>"} {
+		if _, err := keySequenceBytes(spec); err == nil {
+			t.Errorf("keySequenceBytes(%q) should return an error", spec)
+		}
+	}
+}
+
+func TestWidgetKeys(t *testing.T) {
+	bindings := `bindkey -M emacs "^A" beginning-of-line
+bindkey -M emacs "^[[H" beginning-of-line
+bindkey -M emacs "^R" atuin-search
+bindkey -M emacs "^[[A" atuin-up-search
+bindkey -M viins "^R" history-incremental-search-backward
+bindkey -a "/" atuin-search
+bindkey -a "0" vi-digit-or-beginning-of-line
+bindkey -a "k" up-line-or-history
+bindkey "^X^E" edit-command-line
+bindkey -M emacs "\M-a"-"\M-z" self-insert
+`
+
+	tests := []struct {
+		widget   string
+		keymap   string
+		expected string
+		found    bool
+	}{
+		{"beginning-of-line", "emacs", "\x01", true},
+		{"atuin-search", "emacs", "\x12", true},
+		{"atuin-search", "vicmd", "/", true},
+		{"atuin-search", "viins", "", false},
+		{"edit-command-line", "emacs", "\x18\x05", true},
+		{"self-insert", "emacs", "", false},
+		{"vi-digit-or-beginning-of-line", "emacs", "", false},
+		{"up-line-or-history", "vicmd", "k", true},
+		{"up-line-or-history", "emacs", "", false},
+		{"up-line-or-history", "viins", "", false},
+	}
+
+	for _, test := range tests {
+		keys, found := WidgetKeys(bindings, test.widget, test.keymap)
+		if keys != test.expected || found != test.found {
+			t.Errorf("WidgetKeys(%s, %s) = %q, %v, want %q, %v", test.widget, test.keymap, keys, found, test.expected, test.found)
+		}
+	}
+}
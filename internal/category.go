@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+// Categories in the order the picker shows their sections. Config entries
+// may use other names; those sort after these.
+var categoryOrder = []string{
+	"movement", "editing", "history", "completion", "job control",
+	"commands", "aliases", "functions", "custom", "other",
+}
+
+// categoryKeywords assign a category to widgets whose name contains one of
+// the keywords. Earlier entries win, so "up-line-or-history" is history and
+// "backward-kill-word" is editing.
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{"completion", []string{"complete", "list-choices", "list-expand", "expand-word", "menu", "suggest"}},
+	{"history", []string{"history", "search", "hist"}},
+	{"editing", []string{
+		"kill", "delete", "rubout", "discard", "yank", "put", "paste", "copy",
+		"transpose", "swap", "case", "capitalize", "undo", "redo", "insert",
+		"quote", "change", "replace", "edit", "mark", "select", "push",
+		"comment", "join", "vi-add",
+	}},
+	{"movement", []string{
+		"forward", "backward", "beginning", "end-of", "char", "word", "first-non-blank",
+		"bracket", "find", "goto", "jump", "up-line", "down-line", "left", "right",
+	}},
+}
+
+// jobControlKeys are the sequences the terminal turns into signals or flow
+// control rather than line editing.
+var jobControlKeys = []string{"C-c", "C-z", "C-s", "C-q", `C-\`}
+
+// categorize picks the category for a shortcut that has none.
+func categorize(shortcut Shortcut) string {
+	switch shortcut.Type {
+	case "command", "insert":
+		return "commands"
+	case "sequence":
+		if contains(jobControlKeys, shortcut.Target) {
+			return "job control"
+		}
+		return "other"
+	}
+
+	for _, rule := range categoryKeywords {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(shortcut.Target, keyword) {
+				return rule.category
+			}
+		}
+	}
+	return "other"
+}
+
+// categoryOf returns the category a shortcut is grouped under.
+func categoryOf(shortcut Shortcut) string {
+	if shortcut.Category == "" {
+		return "other"
+	}
+	return shortcut.Category
+}
+
+// orderedCategories returns the categories present in shortcuts, known ones
+// first in categoryOrder, then the rest alphabetically.
+func orderedCategories(shortcuts []Shortcut) []string {
+	present := make(map[string]bool)
+	for _, shortcut := range shortcuts {
+		present[categoryOf(shortcut)] = true
+	}
+
+	var categories []string
+	for _, category := range categoryOrder {
+		if present[category] {
+			categories = append(categories, category)
+			delete(present, category)
+		}
+	}
+	var others []string
+	for category := range present {
+		others = append(others, category)
+	}
+	sort.Strings(others)
+
+	return append(categories, others...)
+}
+
+// categoryTitle capitalizes a category for section headers.
+func categoryTitle(category string) string {
+	if category == "" {
+		return category
+	}
+	return strings.ToUpper(category[:1]) + category[1:]
+}
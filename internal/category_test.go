@@ -0,0 +1,75 @@
+package internal
+
+import "testing"
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		shortcut Shortcut
+		expected string
+	}{
+		{Shortcut{Type: "widget", Target: "beginning-of-line"}, "movement"},
+		{Shortcut{Type: "widget", Target: "vi-forward-word"}, "movement"},
+		{Shortcut{Type: "widget", Target: "kill-line"}, "editing"},
+		{Shortcut{Type: "widget", Target: "backward-kill-word"}, "editing"},
+		{Shortcut{Type: "widget", Target: "up-line-or-history"}, "history"},
+		{Shortcut{Type: "widget", Target: "history-incremental-search-backward"}, "history"},
+		{Shortcut{Type: "widget", Target: "expand-or-complete"}, "completion"},
+		{Shortcut{Type: "widget", Target: "complete-and-search"}, "completion"},
+		{Shortcut{Type: "sequence", Target: "C-z"}, "job control"},
+		{Shortcut{Type: "sequence", Target: "C-a sudo SPC"}, "other"},
+		{Shortcut{Type: "command", Target: "git status"}, "commands"},
+		{Shortcut{Type: "widget", Target: "accept-line"}, "other"},
+	}
+
+	for _, test := range tests {
+		if got := categorize(test.shortcut); got != test.expected {
+			t.Errorf("categorize(%s %q) = %q, want %q", test.shortcut.Type, test.shortcut.Target, got, test.expected)
+		}
+	}
+}
+
+func TestBuiltinsAreCategorized(t *testing.T) {
+	for _, shell := range []string{"zsh", "bash", "fish"} {
+		shortcuts, err := getShellShortcuts(shell, ShellState{Aliases: "alias gs='git status'"})
+		if err != nil {
+			t.Fatalf("getShellShortcuts(%s) returned error: %v", shell, err)
+		}
+		counts := make(map[string]int)
+		for _, shortcut := range shortcuts {
+			if !contains(categoryOrder, shortcut.Category) {
+				t.Errorf("%s %s has unknown category %q", shell, shortcut.Display, shortcut.Category)
+			}
+			if shortcut.Source == "" {
+				t.Errorf("%s %s has no source", shell, shortcut.Display)
+			}
+			counts[shortcut.Category]++
+		}
+		for _, category := range []string{"movement", "editing", "history"} {
+			if counts[category] == 0 {
+				t.Errorf("%s has no %s shortcuts", shell, category)
+			}
+		}
+	}
+}
+
+func TestOrderedCategories(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Category: "git"},
+		{Category: "history"},
+		{Category: ""},
+		{Category: "movement"},
+		{Category: "docker"},
+	}
+	expected := []string{"movement", "history", "other", "docker", "git"}
+
+	got := orderedCategories(shortcuts)
+	if len(got) != len(expected) {
+		t.Fatalf("orderedCategories() = %v, want %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("orderedCategories() = %v, want %v", got, expected)
+			break
+		}
+	}
+}
@@ -0,0 +1,599 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigEntry is a shortcut written to the [shortcuts] table by `add`.
+type ConfigEntry struct {
+	Key         string
+	Description string
+	Type        string
+	Target      string
+	Keymap      string
+	Category    string
+}
+
+// ConfigPath returns the location of the user config file.
+func ConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "shortcutter", "config.toml"), nil
+}
+
+// AddConfigShortcut writes entry into the config file, replacing an existing
+// entry for the same key and keymap. The rest of the file, comments included,
+// is kept as is. It reports whether an existing entry was replaced.
+func AddConfigShortcut(entry ConfigEntry) (bool, error) {
+	if strings.TrimSpace(entry.Key) == "" {
+		return false, fmt.Errorf("shortcut key must not be empty")
+	}
+
+	path, content, err := readConfigFile()
+	if err != nil {
+		return false, err
+	}
+
+	updated, replaced, err := addConfigEntry(content, entry)
+	if err != nil {
+		return false, err
+	}
+	if err := writeConfigFile(path, updated); err != nil {
+		return false, err
+	}
+	return replaced, nil
+}
+
+// RemoveConfigShortcut deletes the entries for key in keymap from the config
+// file and returns how many were removed. An empty keymap also matches the
+// entries of a key configured in a single keymap; it is an error when the
+// key is configured in several. Built-ins are not affected.
+func RemoveConfigShortcut(key string, keymap string) (int, error) {
+	path, content, err := readConfigFile()
+	if err != nil {
+		return 0, err
+	}
+
+	updated, removed, err := removeConfigEntry(content, key, keymap)
+	if err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := writeConfigFile(path, updated); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func readConfigFile() (string, string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return path, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return path, string(data), nil
+}
+
+func writeConfigFile(path string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+var (
+	tableHeaderPattern = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?\s*(#.*)?$`)
+	bareKeyPattern     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// configBlock is a range of lines in the config file holding one shortcut,
+// either a `key = value` line in [shortcuts], a [shortcuts.key] table or one
+// [[shortcuts.key]] table of an array, which holds a key in several keymaps.
+type configBlock struct {
+	start, end int // end is exclusive
+	key        string
+	array      bool
+}
+
+// scanShortcutsTable finds the shortcut entries in the config text, plus the
+// line after the last entry of the [shortcuts] table (-1 if there is none).
+func scanShortcutsTable(lines []string) ([]configBlock, int) {
+	var blocks []configBlock
+	insertAt := -1
+	table := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if match := tableHeaderPattern.FindStringSubmatch(line); match != nil {
+			table = match[1]
+			if table == "shortcuts" {
+				insertAt = i + 1
+			}
+			if path := splitDottedKey(table); len(path) == 2 && path[0] == "shortcuts" {
+				end := i + 1
+				for end < len(lines) && tableHeaderPattern.FindStringSubmatch(lines[end]) == nil {
+					end++
+				}
+				end = trimTrailingBlankLines(lines, i+1, end)
+				array := strings.HasPrefix(strings.TrimSpace(line), "[[")
+				blocks = append(blocks, configBlock{start: i, end: end, key: path[1], array: array})
+			}
+			continue
+		}
+
+		if table != "shortcuts" || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		key, value, ok := splitKeyValue(trimmed)
+		if !ok {
+			continue
+		}
+		end := i + 1
+		if delim := openMultilineString(value); delim != "" {
+			for end < len(lines) && !strings.Contains(lines[end], delim) {
+				end++
+			}
+			if end < len(lines) {
+				end++
+			}
+		}
+		blocks = append(blocks, configBlock{start: i, end: end, key: key})
+		insertAt = end
+		i = end - 1
+	}
+
+	return blocks, insertAt
+}
+
+// addConfigEntry returns content with entry added, or replacing the entry
+// for the same key and keymap. Adding a key already written for another
+// keymap turns its entries into a [[shortcuts.key]] array.
+func addConfigEntry(content string, entry ConfigEntry) (string, bool, error) {
+	lines := splitConfigLines(content)
+	blocks, insertAt := scanShortcutsTable(lines)
+	line := formatConfigEntry(entry)
+
+	var clash []configBlock // Entries under the same TOML key in other keymaps
+	for _, block := range blocks {
+		if normalizeKey(block.key) != normalizeKey(entry.Key) {
+			continue
+		}
+		if blockKeymap(lines, block) != entry.Keymap {
+			if block.key == strings.TrimSpace(entry.Key) {
+				clash = append(clash, block)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(lines[block.start]), "[") {
+			// Keep the table form so surrounding comments stay in place
+			lines = replaceLines(lines, block.start, block.end, formatConfigTable(entry, block.array))
+		} else {
+			comment := trailingComment(lines[block.start])
+			if block.end-block.start == 1 && comment != "" {
+				line += "  " + comment
+			}
+			lines = replaceLines(lines, block.start, block.end, []string{line})
+		}
+		return joinConfigLines(lines), true, nil
+	}
+
+	if len(clash) > 0 {
+		return addConfigArrayEntry(lines, clash, entry)
+	}
+
+	if insertAt < 0 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "[shortcuts]", line)
+		return joinConfigLines(lines), false, nil
+	}
+
+	lines = replaceLines(lines, insertAt, insertAt, []string{line})
+	return joinConfigLines(lines), false, nil
+}
+
+// addConfigArrayEntry adds entry as a [[shortcuts.key]] table after clash,
+// the entries written under the same key for other keymaps, turning those
+// into tables of the array first.
+func addConfigArrayEntry(lines []string, clash []configBlock, entry ConfigEntry) (string, bool, error) {
+	tables := []string{""}
+	last := clash[len(clash)-1]
+	insertAt := last.end
+
+	switch first := clash[0]; {
+	case first.array:
+	case strings.HasPrefix(strings.TrimSpace(lines[first.start]), "["):
+		lines[first.start] = "[" + strings.TrimSpace(lines[first.start]) + "]"
+	default:
+		// A `key = value` line can't sit next to the array, so it moves to
+		// the array's first table
+		table, err := inlineEntryTable(lines, first)
+		if err != nil {
+			return "", false, err
+		}
+		lines = replaceLines(lines, first.start, first.end, nil)
+		insertAt = len(lines)
+		tables = append(append(tables, table...), "")
+	}
+
+	tables = append(tables, formatConfigTable(entry, true)...)
+	lines = replaceLines(lines, insertAt, insertAt, tables)
+	return joinConfigLines(lines), false, nil
+}
+
+// inlineEntryTable renders the `key = value` line of block as a
+// [[shortcuts.key]] table with the same fields.
+func inlineEntryTable(lines []string, block configBlock) ([]string, error) {
+	var pair map[string]interface{}
+	if _, err := toml.Decode(strings.Join(lines[block.start:block.end], "\n"), &pair); err != nil {
+		return nil, err
+	}
+
+	var table []string
+	if comment := trailingComment(lines[block.start]); comment != "" && block.end-block.start == 1 {
+		table = append(table, comment)
+	}
+	table = append(table, fmt.Sprintf("[[shortcuts.%s]]", tomlKey(block.key)))
+	for _, value := range pair {
+		switch v := value.(type) {
+		case string:
+			table = append(table, fmt.Sprintf("description = %s", tomlString(v)))
+		case map[string]interface{}:
+			// Known fields in the order `add` writes them, then the rest
+			fields := sortedKeys(v)
+			sort.SliceStable(fields, func(i, j int) bool {
+				return fieldRank(fields[i]) < fieldRank(fields[j])
+			})
+			for _, field := range fields {
+				text, ok := v[field].(string)
+				if !ok {
+					return nil, fmt.Errorf("'%s' has a %s field '%s' that can't be moved into a table", block.key, tomlTypeName(v[field]), field)
+				}
+				table = append(table, fmt.Sprintf("%s = %s", tomlKey(field), tomlString(text)))
+			}
+		default:
+			return nil, fmt.Errorf("'%s' is set to %s for every keymap; remove it before adding it for one keymap", block.key, tomlTypeName(value))
+		}
+	}
+	return table, nil
+}
+
+// fieldRank orders shortcut fields as shortcutFields lists them, unknown
+// fields last.
+func fieldRank(field string) int {
+	for i, known := range shortcutFields {
+		if known == field {
+			return i
+		}
+	}
+	return len(shortcutFields)
+}
+
+// blockKeymap returns the keymap of the shortcut in block, empty when it
+// applies in every keymap.
+func blockKeymap(lines []string, block configBlock) string {
+	var table map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(lines[block.start]), "[") {
+		if _, err := toml.Decode(strings.Join(lines[block.start+1:block.end], "\n"), &table); err != nil {
+			return ""
+		}
+	} else {
+		var pair map[string]interface{}
+		if _, err := toml.Decode(strings.Join(lines[block.start:block.end], "\n"), &pair); err != nil {
+			return ""
+		}
+		for _, value := range pair {
+			table, _ = value.(map[string]interface{})
+		}
+	}
+	keymap, _ := table["keymap"].(string)
+	return keymap
+}
+
+// removeConfigEntry returns content without the entries for key in keymap,
+// see RemoveConfigShortcut.
+func removeConfigEntry(content string, key string, keymap string) (string, int, error) {
+	lines := splitConfigLines(content)
+	blocks, _ := scanShortcutsTable(lines)
+
+	var matches []configBlock
+	keymaps := make(map[string]bool)
+	for _, block := range blocks {
+		if normalizeKey(block.key) != normalizeKey(key) {
+			continue
+		}
+		blockKeymap := blockKeymap(lines, block)
+		keymaps[blockKeymap] = true
+		if blockKeymap == keymap {
+			matches = append(matches, block)
+		}
+	}
+
+	if keymap == "" && len(matches) == 0 {
+		if len(keymaps) > 1 {
+			var names []string
+			for name := range keymaps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return content, 0, fmt.Errorf("'%s' is configured for keymaps %s; choose one with --keymap", strings.TrimSpace(key), strings.Join(names, ", "))
+		}
+		for _, block := range blocks {
+			if normalizeKey(block.key) == normalizeKey(key) {
+				matches = append(matches, block)
+			}
+		}
+	}
+
+	for i := len(matches) - 1; i >= 0; i-- {
+		lines = replaceLines(lines, matches[i].start, matches[i].end, nil)
+	}
+
+	return joinConfigLines(lines), len(matches), nil
+}
+
+// formatConfigEntry renders entry as a single `key = value` line. A bare
+// description becomes the short string form, anything else an inline table.
+func formatConfigEntry(entry ConfigEntry) string {
+	if entry.Type == "" && entry.Target == "" && entry.Keymap == "" && entry.Category == "" {
+		return fmt.Sprintf("%s = %s", tomlKey(entry.Key), tomlString(entry.Description))
+	}
+
+	fields := configEntryFields(entry)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = fmt.Sprintf("%s = %s", field[0], tomlString(field[1]))
+	}
+	return fmt.Sprintf("%s = { %s }", tomlKey(entry.Key), strings.Join(parts, ", "))
+}
+
+// formatConfigTable renders entry as a [shortcuts.key] table, or as one
+// [[shortcuts.key]] table of an array.
+func formatConfigTable(entry ConfigEntry, array bool) []string {
+	header := fmt.Sprintf("[shortcuts.%s]", tomlKey(entry.Key))
+	if array {
+		header = "[" + header + "]"
+	}
+	lines := []string{header}
+	for _, field := range configEntryFields(entry) {
+		lines = append(lines, fmt.Sprintf("%s = %s", field[0], tomlString(field[1])))
+	}
+	return lines
+}
+
+func configEntryFields(entry ConfigEntry) [][2]string {
+	var fields [][2]string
+	for _, field := range [][2]string{
+		{"description", entry.Description},
+		{"type", entry.Type},
+		{"target", entry.Target},
+		{"keymap", entry.Keymap},
+		{"category", entry.Category},
+	} {
+		if field[1] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// tomlKey quotes key unless it is a valid bare TOML key.
+func tomlKey(key string) string {
+	if bareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString renders s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// splitKeyValue splits a `key = value` line, unquoting the key.
+func splitKeyValue(line string) (string, string, bool) {
+	var key string
+	var rest string
+
+	if line == "" {
+		return "", "", false
+	}
+
+	switch line[0] {
+	case '"', '\'':
+		end := closingQuote(line, line[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key = unquoteTOMLKey(line[:end+1])
+		rest = line[end+1:]
+	default:
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return "", "", false
+		}
+		key = strings.TrimSpace(line[:eq])
+		rest = line[eq:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(rest[1:]), true
+}
+
+// splitDottedKey splits a table name like `shortcuts."Ctrl+X"` into parts.
+func splitDottedKey(name string) []string {
+	var parts []string
+	for name != "" {
+		name = strings.TrimSpace(name)
+		var part string
+		if name[0] == '"' || name[0] == '\'' {
+			end := closingQuote(name, name[0])
+			if end < 0 {
+				return nil
+			}
+			part = unquoteTOMLKey(name[:end+1])
+			name = strings.TrimSpace(name[end+1:])
+		} else {
+			dot := strings.IndexByte(name, '.')
+			if dot < 0 {
+				dot = len(name)
+			}
+			part = strings.TrimSpace(name[:dot])
+			name = name[dot:]
+		}
+		parts = append(parts, part)
+		if name != "" {
+			if name[0] != '.' {
+				return nil
+			}
+			name = name[1:]
+		}
+	}
+	return parts
+}
+
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteTOMLKey(s string) string {
+	if s[0] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// trailingComment returns the `# comment` at the end of a line, if any.
+func trailingComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"', '\'':
+			end := closingQuote(line[i:], line[i])
+			if end < 0 {
+				return ""
+			}
+			i += end
+		case '#':
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// openMultilineString returns the closing delimiter if value starts a
+// multi-line string that continues on the following lines.
+func openMultilineString(value string) string {
+	for _, delim := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(value, delim) && !strings.Contains(value[3:], delim) {
+			return delim
+		}
+	}
+	return ""
+}
+
+func trimTrailingBlankLines(lines []string, start, end int) int {
+	for end > start {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+func replaceLines(lines []string, start, end int, replacement []string) []string {
+	result := make([]string, 0, len(lines)-(end-start)+len(replacement))
+	result = append(result, lines[:start]...)
+	result = append(result, replacement...)
+	return append(result, lines[end:]...)
+}
+
+func splitConfigLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+func joinConfigLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+const sampleConfig = `# My shortcutter config
+[theme]
+name = "dracula"
+
+[shortcuts]
+# git helpers
+gs = "git status"  # quick status
+"Ctrl+G" = { description = "Git status" }
+
+# log viewer
+[shortcuts.gl]
+description = "Pretty git log"
+target = "git log --oneline"
+
+# end of file
+`
+
+func TestAddConfigEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		entry    ConfigEntry
+		replaced bool
+		contains []string
+	}{
+		{
+			name:     "new command after last entry",
+			content:  sampleConfig,
+			entry:    ConfigEntry{Key: "gd", Description: `Show "diff"`, Type: "command", Target: "git diff"},
+			replaced: false,
+			contains: []string{
+				"\"Ctrl+G\" = { description = \"Git status\" }\ngd = { description = \"Show \\\"diff\\\"\", type = \"command\", target = \"git diff\" }\n",
+			},
+		},
+		{
+			name:     "replace keeps trailing comment",
+			content:  sampleConfig,
+			entry:    ConfigEntry{Key: "gs", Description: "Short status"},
+			replaced: true,
+			contains: []string{`gs = "Short status"  # quick status`},
+		},
+		{
+			name:     "replace matches any key notation",
+			content:  sampleConfig,
+			entry:    ConfigEntry{Key: "C-g", Type: "command", Target: "git status"},
+			replaced: true,
+			contains: []string{`C-g = { type = "command", target = "git status" }`},
+		},
+		{
+			name:     "replace sub-table",
+			content:  sampleConfig,
+			entry:    ConfigEntry{Key: "gl", Target: "git log --graph", Type: "command"},
+			replaced: true,
+			contains: []string{"# log viewer\n[shortcuts.gl]\ntype = \"command\"\ntarget = \"git log --graph\"\n\n# end of file\n"},
+		},
+		{
+			name:     "category forces the table form",
+			content:  "",
+			entry:    ConfigEntry{Key: "gs", Description: "git st", Category: "favourites"},
+			replaced: false,
+			contains: []string{`gs = { description = "git st", category = "favourites" }`},
+		},
+		{
+			name:     "missing table is appended",
+			content:  "[theme]\nname = \"nord\"\n",
+			entry:    ConfigEntry{Key: "Ctrl+O", Description: "Open", Target: "open .", Type: "command"},
+			replaced: false,
+			contains: []string{"name = \"nord\"\n\n[shortcuts]\n\"Ctrl+O\" = {"},
+		},
+		{
+			name:     "empty file",
+			content:  "",
+			entry:    ConfigEntry{Key: "ll", Description: "ls -lh"},
+			replaced: false,
+			contains: []string{"[shortcuts]\nll = \"ls -lh\"\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, replaced, err := addConfigEntry(test.content, test.entry)
+			if err != nil {
+				t.Fatalf("addConfigEntry() returned error: %v", err)
+			}
+			if replaced != test.replaced {
+				t.Errorf("addConfigEntry() replaced = %v, want %v", replaced, test.replaced)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("addConfigEntry() result missing %q:\n%s", want, result)
+				}
+			}
+			if strings.HasPrefix(test.content, "#") && !strings.HasPrefix(result, "# My shortcutter config\n") {
+				t.Errorf("addConfigEntry() dropped leading comment:\n%s", result)
+			}
+
+			var config Config
+			if _, err := toml.Decode(result, &config); err != nil {
+				t.Errorf("addConfigEntry() produced invalid TOML: %v\n%s", err, result)
+			}
+		})
+	}
+}
+
+func TestRemoveConfigEntry(t *testing.T) {
+	result, removed, _ := removeConfigEntry(sampleConfig, "gs", "")
+	if removed != 1 {
+		t.Errorf("removeConfigEntry(gs) removed %d, want 1", removed)
+	}
+	if strings.Contains(result, "gs =") || !strings.Contains(result, "# git helpers") {
+		t.Errorf("removeConfigEntry(gs) result:\n%s", result)
+	}
+
+	result, removed, _ = removeConfigEntry(sampleConfig, "gl", "")
+	if removed != 1 {
+		t.Errorf("removeConfigEntry(gl) removed %d, want 1", removed)
+	}
+	if strings.Contains(result, "[shortcuts.gl]") || strings.Contains(result, "Pretty git log") {
+		t.Errorf("removeConfigEntry(gl) left the table behind:\n%s", result)
+	}
+	if !strings.Contains(result, "# end of file") {
+		t.Errorf("removeConfigEntry(gl) removed a following comment:\n%s", result)
+	}
+
+	if _, removed, _ := removeConfigEntry(sampleConfig, "missing", ""); removed != 0 {
+		t.Errorf("removeConfigEntry(missing) removed %d, want 0", removed)
+	}
+}
+
+func TestConfigEntryKeymaps(t *testing.T) {
+	content, _, err := addConfigEntry("", ConfigEntry{Key: "x", Type: "widget", Target: "vi-delete-char", Keymap: "vicmd"})
+	if err != nil {
+		t.Fatalf("addConfigEntry(x vicmd) returned error: %v", err)
+	}
+	content, replaced, err := addConfigEntry(content, ConfigEntry{Key: "x", Type: "widget", Target: "kill-region", Keymap: "visual"})
+	if err != nil || replaced {
+		t.Fatalf("addConfigEntry(x visual) = replaced %v, error %v; want a new entry", replaced, err)
+	}
+	content, replaced, _ = addConfigEntry(content, ConfigEntry{Key: "x", Description: "Cut", Type: "widget", Target: "kill-region", Keymap: "visual"})
+	if !replaced {
+		t.Errorf("addConfigEntry(x visual) again should replace the visual entry:\n%s", content)
+	}
+
+	var config Config
+	if _, err := toml.Decode(content, &config); err != nil {
+		t.Fatalf("keymap entries produced invalid TOML: %v\n%s", err, content)
+	}
+	var keymaps []string
+	for _, shortcut := range mergeShortcuts(nil, &config) {
+		keymaps = append(keymaps, shortcut.Keymap+"="+shortcut.Target)
+	}
+	if got := strings.Join(keymaps, ","); got != "vicmd=vi-delete-char,visual=kill-region" {
+		t.Errorf("merged keymap entries = %s, want vicmd and visual\n%s", got, content)
+	}
+	if diagnostics := validateConfig(content, nil); len(diagnostics) != 0 {
+		t.Errorf("validateConfig() on keymap entries = %+v, want none", diagnostics)
+	}
+
+	if _, _, err := removeConfigEntry(content, "x", ""); err == nil {
+		t.Error("removeConfigEntry(x) without a keymap should be ambiguous")
+	}
+	result, removed, err := removeConfigEntry(content, "x", "visual")
+	if err != nil || removed != 1 {
+		t.Fatalf("removeConfigEntry(x visual) = %d, %v; want 1", removed, err)
+	}
+	if strings.Contains(result, "kill-region") || !strings.Contains(result, "vi-delete-char") {
+		t.Errorf("removeConfigEntry(x visual) should keep the vicmd entry:\n%s", result)
+	}
+	if _, removed, err := removeConfigEntry(result, "x", ""); err != nil || removed != 1 {
+		t.Errorf("removeConfigEntry(x) with one keymap left = %d, %v; want 1", removed, err)
+	}
+}
+
+func TestConfigEntryKeymapsFromInlineAndTable(t *testing.T) {
+	inline := "[shortcuts]\nx = { type = \"widget\", target = \"vi-delete-char\", keymap = \"vicmd\" }  # delete\n"
+	table := "[shortcuts.x]\n# delete under the cursor\ntype = \"widget\"\ntarget = \"vi-delete-char\"\nkeymap = \"vicmd\"\n"
+
+	for name, content := range map[string]string{"inline": inline, "table": table} {
+		result, _, err := addConfigEntry(content, ConfigEntry{Key: "x", Type: "widget", Target: "kill-region", Keymap: "visual"})
+		if err != nil {
+			t.Fatalf("%s: addConfigEntry() returned error: %v", name, err)
+		}
+		var config Config
+		if _, err := toml.Decode(result, &config); err != nil {
+			t.Fatalf("%s: invalid TOML: %v\n%s", name, err, result)
+		}
+		if tables, ok := config.Shortcuts["x"].([]map[string]interface{}); !ok || len(tables) != 2 {
+			t.Errorf("%s: x should become an array of two tables:\n%s", name, result)
+		}
+	}
+
+	if _, _, err := addConfigEntry("[shortcuts]\nx = false\n", ConfigEntry{Key: "x", Type: "widget", Target: "kill-region", Keymap: "visual"}); err == nil {
+		t.Error("addConfigEntry() should refuse to turn a disabled key into keymap entries")
+	}
+}
+
+func TestAddAndRemoveConfigShortcut(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := AddConfigShortcut(ConfigEntry{Key: "gs", Description: "Git status", Type: "command", Target: "git status"}); err != nil {
+		t.Fatalf("AddConfigShortcut() returned error: %v", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() returned error: %v", err)
+	}
+	if _, ok := config.Shortcuts["gs"]; !ok {
+		t.Errorf("loadConfig() after add = %+v, want gs entry", config.Shortcuts)
+	}
+
+	removed, err := RemoveConfigShortcut("gs", "")
+	if err != nil || removed != 1 {
+		t.Fatalf("RemoveConfigShortcut() = %d, %v", removed, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".config", "shortcutter", "config.toml"))
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if strings.Contains(string(data), "gs") {
+		t.Errorf("config after remove = %q", data)
+	}
+}
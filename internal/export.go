@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ExportFormats are the document formats `export` can write.
+var ExportFormats = []string{"md", "html", "man"}
+
+// IsExportFormat reports whether format is one of ExportFormats.
+func IsExportFormat(format string) bool {
+	return contains(ExportFormats, format)
+}
+
+// exportDocument is the data the export templates render.
+type exportDocument struct {
+	Title    string
+	Date     string
+	Keymaps  bool // Show the keymap column: the shortcuts span several keymaps
+	Custom   bool // Some shortcuts come from the config
+	Sections []exportSection
+	Theme    Theme
+}
+
+// exportSection is one category of the cheat sheet.
+type exportSection struct {
+	Title     string
+	Shortcuts []exportRow
+}
+
+type exportRow struct {
+	Key         string
+	Description string
+	Target      string
+	Keymap      string
+	Custom      bool
+}
+
+// ExportShortcuts writes shortcuts as a standalone cheat sheet in one of
+// ExportFormats, grouped by category, with keys rendered in keyStyle. The
+// HTML document takes its colours from theme.
+func ExportShortcuts(w io.Writer, shortcuts []Shortcut, format string, theme Theme, keyStyle string) error {
+	document := newExportDocument(shortcuts, theme, keyStyle, time.Now())
+
+	switch format {
+	case "md":
+		return markdownTemplate.Execute(w, document)
+	case "html":
+		return htmlTemplate.Execute(w, document)
+	case "man":
+		return manTemplate.Execute(w, document)
+	default:
+		return fmt.Errorf("unknown export format '%s' - use md, html or man", format)
+	}
+}
+
+func newExportDocument(shortcuts []Shortcut, theme Theme, keyStyle string, now time.Time) exportDocument {
+	document := exportDocument{
+		Title:   "Shell shortcuts",
+		Date:    now.Format("2006-01-02"),
+		Keymaps: len(availableKeymaps(shortcuts)) > 1,
+		Theme:   theme,
+	}
+
+	for _, category := range orderedCategories(shortcuts) {
+		section := exportSection{Title: categoryTitle(category)}
+		for _, shortcut := range shortcuts {
+			if categoryOf(shortcut) != category {
+				continue
+			}
+			section.Shortcuts = append(section.Shortcuts, exportRow{
+				Key:         shortcut.KeyLabel(keyStyle),
+				Description: shortcut.Description,
+				Target:      shortcut.Target,
+				Keymap:      shortcut.Keymap,
+				Custom:      shortcut.IsCustom,
+			})
+			document.Custom = document.Custom || shortcut.IsCustom
+		}
+		document.Sections = append(document.Sections, section)
+	}
+	return document
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// markdownCode renders text as a code span in a table cell, with enough
+// backticks to hold any inside it.
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + strings.ReplaceAll(text, "|", "\\|") + fence
+}
+
+// roff escapes text for a man page line.
+func roff(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	text = strings.ReplaceAll(text, "\n", " ")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
+
+var markdownTemplate = template.Must(template.New("md").Funcs(template.FuncMap{
+	"cell": markdownCell,
+	"code": markdownCode,
+}).Parse(`# {{.Title}}
+{{range .Sections}}
+## {{.Title}}
+
+| Key | Description | Target |{{if $.Keymaps}} Keymap |{{end}}
+|-----|-------------|--------|{{if $.Keymaps}}--------|{{end}}
+{{range .Shortcuts}}| {{code .Key}}{{if .Custom}} \*{{end}} | {{cell .Description}} | {{code .Target}} |{{if $.Keymaps}} {{.Keymap}} |{{end}}
+{{end}}{{end}}{{if .Custom}}
+\* Custom entry from the shortcutter config.
+{{end}}`))
+
+var manTemplate = template.Must(template.New("man").Funcs(template.FuncMap{
+	"roff":  roff,
+	"upper": strings.ToUpper,
+}).Parse(`.TH SHORTCUTTER 7 "{{.Date}}" "shortcutter" "{{.Title}}"
+.SH NAME
+shortcutter \- shell shortcut cheat sheet
+{{- range .Sections}}
+.SH {{upper .Title | roff}}
+{{- range .Shortcuts}}
+.TP
+.B {{roff .Key}}{{if $.Keymaps}}{{if .Keymap}} ({{.Keymap}}){{end}}{{end}}
+{{roff .Description}}{{if .Custom}} [custom]{{end}}{{if .Target}}
+.br
+.I {{roff .Target}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .Custom}}
+.SH NOTES
+Entries marked [custom] come from the shortcutter config.
+{{- end}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+	"background": func(theme Theme) string {
+		if theme.AppBg == "" || theme.AppBg == "transparent" || theme.AppBg == "default" {
+			return "#1F1F1F"
+		}
+		return theme.AppBg
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 2rem auto; max-width: 60rem; padding: 0 1rem; font-family: system-ui, sans-serif; background: {{background .Theme}}; color: {{.Theme.Query}}; }
+h1, h2 { color: {{.Theme.Primary}}; }
+h2 { border-bottom: 1px solid {{.Theme.Border}}; padding-bottom: .25rem; }
+input { width: 100%; box-sizing: border-box; padding: .5rem; font-size: 1rem; color: {{.Theme.Query}}; background: {{.Theme.SelectedBg}}; border: 1px solid {{.Theme.Border}}; border-radius: 4px; }
+input:focus { outline: 2px solid {{.Theme.Accent}}; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: .3rem .5rem; vertical-align: top; }
+tr:hover { background: {{.Theme.SelectedBg}}; }
+kbd { font-family: ui-monospace, monospace; font-weight: bold; color: {{.Theme.Primary}}; white-space: nowrap; }
+code { color: {{.Theme.Secondary}}; }
+.description { color: {{.Theme.Muted}}; }
+.keymap, .note { color: {{.Theme.Help}}; }
+.custom { color: {{.Theme.CustomIndicator}}; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<input id="search" type="search" placeholder="Search shortcuts" autofocus>
+{{range .Sections}}<section>
+<h2>{{.Title}}</h2>
+<table>
+{{range .Shortcuts}}<tr><td><kbd>{{.Key}}</kbd>{{if .Custom}} <span class="custom" title="Custom entry">*</span>{{end}}</td><td class="description">{{.Description}}</td><td><code>{{.Target}}</code></td>{{if $.Keymaps}}<td class="keymap">{{.Keymap}}</td>{{end}}</tr>
+{{end}}</table>
+</section>
+{{end}}{{if .Custom}}<p class="note"><span class="custom">*</span> Custom entry from the shortcutter config.</p>
+{{end}}<script>
+document.getElementById("search").addEventListener("input", function (event) {
+  var terms = event.target.value.toLowerCase().split(/\s+/).filter(Boolean);
+  document.querySelectorAll("section").forEach(function (section) {
+    var shown = 0;
+    section.querySelectorAll("tr").forEach(function (row) {
+      var text = row.textContent.toLowerCase();
+      var match = terms.every(function (term) { return text.indexOf(term) >= 0; });
+      row.hidden = !match;
+      if (match) { shown++; }
+    });
+    section.hidden = shown === 0;
+  });
+});
+</script>
+</body>
+</html>
+`))
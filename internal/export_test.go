@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func exportTestShortcuts() []Shortcut {
+	keys := func(spec string) KeySequence {
+		sequence, _ := ParseKeySequence(spec)
+		return sequence
+	}
+	return []Shortcut{
+		{Display: "Ctrl+A", Keys: keys("Ctrl+A"), Description: "Beginning of the line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs", Category: "movement"},
+		{Display: "Ctrl+R", Keys: keys("Ctrl+R"), Description: "Search history", Type: "widget", Target: "history-incremental-search-backward", Keymap: "emacs", Category: "history"},
+		{Display: "gp", Description: "Push | pull <branch>", Type: "command", Target: "git push", IsCustom: true, Category: "custom"},
+		{Display: ".", Description: ".Repeat last change", Type: "widget", Target: "vi-repeat-change", Keymap: "vicmd", Category: "editing"},
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := ExportShortcuts(&b, exportTestShortcuts(), "md", GetDefaultTheme(), "emacs"); err != nil {
+		t.Fatalf("ExportShortcuts(md) returned error: %v", err)
+	}
+	output := b.String()
+
+	for _, expected := range []string{
+		"# Shell shortcuts",
+		"## Movement\n",
+		"| `C-a` | Beginning of the line | `beginning-of-line` | emacs |",
+		"| `gp` \\* | Push \\| pull <branch> | `git push` |  |",
+		"\\* Custom entry",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Markdown export should contain %q:\n%s", expected, output)
+		}
+	}
+	if strings.Index(output, "## Movement") > strings.Index(output, "## History") {
+		t.Error("Sections should follow the category order")
+	}
+}
+
+func TestExportMan(t *testing.T) {
+	var b strings.Builder
+	if err := ExportShortcuts(&b, exportTestShortcuts(), "man", GetDefaultTheme(), ""); err != nil {
+		t.Fatalf("ExportShortcuts(man) returned error: %v", err)
+	}
+	output := b.String()
+
+	for _, expected := range []string{
+		".TH SHORTCUTTER 7",
+		".SH MOVEMENT\n.TP\n.B Ctrl+A (emacs)\nBeginning of the line\n.br\n.I beginning\\-of\\-line\n",
+		"\\&.Repeat last change",
+		"[custom]",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Man page export should contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	theme := GetDefaultTheme()
+	theme.Primary = "#123456"
+
+	var b strings.Builder
+	if err := ExportShortcuts(&b, exportTestShortcuts(), "html", theme, ""); err != nil {
+		t.Fatalf("ExportShortcuts(html) returned error: %v", err)
+	}
+	output := b.String()
+
+	for _, expected := range []string{
+		"<!DOCTYPE html>",
+		"color: #123456",
+		`<input id="search"`,
+		"<h2>History</h2>",
+		"Push | pull &lt;branch&gt;",
+		`<span class="custom"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("HTML export should contain %q", expected)
+		}
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if IsExportFormat("pdf") {
+		t.Error("pdf should not be an export format")
+	}
+	if err := ExportShortcuts(&strings.Builder{}, nil, "pdf", GetDefaultTheme(), ""); err == nil {
+		t.Error("ExportShortcuts(pdf) should return an error")
+	}
+}
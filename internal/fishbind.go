@@ -0,0 +1,431 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fishBindEntry is a single binding parsed from fish `bind` output.
+type fishBindEntry struct {
+	Mode     string   // Bind mode given with -M, empty for the default mode
+	Keys     string   // Raw key sequence (decoded bytes), empty for named keys
+	Named    string   // Key name from `-k name` or fish 4 notation like "ctrl-x,ctrl-e"
+	Commands []string // Input functions or commands run by the binding
+}
+
+// parseFishBindOutput converts a dump of fish `bind` into shortcuts. Only the
+// default bind mode is included; self-insert and no-op bindings are skipped.
+func parseFishBindOutput(output string) []Shortcut {
+	var shortcuts []Shortcut
+	for _, line := range strings.Split(output, "\n") {
+		entry, ok := parseFishBindLine(line)
+		if !ok {
+			continue
+		}
+		if entry.Mode != "" && entry.Mode != "default" {
+			continue
+		}
+		if len(entry.Commands) == 1 {
+			switch entry.Commands[0] {
+			case "self-insert", "self-insert-notfirst", "do-nothing":
+				continue
+			}
+		}
+		shortcuts = append(shortcuts, entry.shortcut())
+	}
+	return shortcuts
+}
+
+func parseFishBindLine(line string) (fishBindEntry, bool) {
+	var entry fishBindEntry
+
+	tokens := tokenizeFishLine(strings.TrimSpace(line))
+	if len(tokens) == 0 || tokens[0].text != "bind" {
+		return entry, false
+	}
+	tokens = tokens[1:]
+
+	named := false
+flags:
+	for len(tokens) > 0 && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "-") {
+		switch tokens[0].text {
+		case "-M", "--mode", "-m", "--sets-mode":
+			if len(tokens) < 2 {
+				return entry, false
+			}
+			if tokens[0].text == "-M" || tokens[0].text == "--mode" {
+				entry.Mode = tokens[1].text
+			}
+			tokens = tokens[1:]
+		case "-k", "--key":
+			named = true
+		case "--":
+			tokens = tokens[1:]
+			break flags
+		}
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) < 2 {
+		return entry, false
+	}
+
+	key := tokens[0]
+	switch {
+	case named:
+		entry.Named = key.text
+	case !key.quoted && isFishKeyName(key.text):
+		entry.Named = key.text
+	case key.quoted:
+		entry.Keys = key.text
+	default:
+		entry.Keys = decodeFishEscapes(key.text)
+	}
+
+	for _, token := range tokens[1:] {
+		entry.Commands = append(entry.Commands, token.text)
+	}
+
+	return entry, true
+}
+
+func (e fishBindEntry) shortcut() Shortcut {
+	keys := keySequenceFromBytes(e.Keys)
+	display := keys.Display()
+	if e.Named != "" {
+		display = displayFishKeyName(e.Named)
+		// Terminfo names such as "dc" have no chord notation
+		keys, _ = ParseKeySequence(display)
+	}
+
+	if len(e.Commands) == 1 && !strings.ContainsAny(e.Commands[0], " \t;") {
+		return Shortcut{
+			Display:     display,
+			Keys:        keys,
+			Description: describeFishFunction(e.Commands[0]),
+			Type:        "widget",
+			Target:      e.Commands[0],
+			IsCustom:    false,
+		}
+	}
+
+	command := strings.Join(e.Commands, "; ")
+	return Shortcut{
+		Display:     display,
+		Keys:        keys,
+		Description: command,
+		Type:        "command",
+		Target:      command,
+		IsCustom:    false,
+	}
+}
+
+// FishSequenceTarget returns what the keys of a sequence shortcut are bound
+// to in fish, from the live `bind` dump: the input functions or commands of
+// the binding, separated by "; ". fish can't replay raw keys, so the
+// integration runs these instead. Keys fish doesn't bind give "".
+func FishSequenceTarget(shortcut Shortcut, bindings string) string {
+	keys, err := keySequenceBytes(shortcut.Target)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(bindings, "\n") {
+		entry, ok := parseFishBindLine(line)
+		if !ok || (entry.Mode != "" && entry.Mode != "default" && entry.Mode != "insert") {
+			continue
+		}
+		if entry.shortcut().Keys.Bytes() == keys {
+			return strings.Join(entry.Commands, "; ")
+		}
+	}
+	return ""
+}
+
+type fishToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeFishLine splits a line of fish code into words. Quotes are removed,
+// but backslash escapes outside quotes are kept so key sequences can be
+// decoded separately.
+func tokenizeFishLine(line string) []fishToken {
+	var tokens []fishToken
+	var word strings.Builder
+	inWord := false
+	quoted := false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, fishToken{text: word.String(), quoted: quoted})
+			word.Reset()
+			inWord = false
+			quoted = false
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			flush()
+		case c == '\'' || c == '"':
+			inWord = true
+			quoted = true
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' && i+1 < len(line) && (line[i+1] == c || line[i+1] == '\\' || (c == '"' && line[i+1] == '$')) {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '\\' && i+1 < len(line):
+			inWord = true
+			word.WriteByte(c)
+			i++
+			word.WriteByte(line[i])
+		default:
+			inWord = true
+			word.WriteByte(c)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// decodeFishEscapes converts fish escape notation like `\cx`, `\e[A` or
+// `\x7f` into raw key bytes.
+func decodeFishEscapes(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'c':
+			if i+1 < len(s) {
+				i++
+				if s[i] == '?' {
+					b.WriteByte(0x7f)
+				} else {
+					b.WriteByte(s[i] & 0x1f)
+				}
+			}
+		case 'e':
+			b.WriteByte(0x1b)
+		case 'a':
+			b.WriteByte(0x07)
+		case 'b':
+			b.WriteByte(0x08)
+		case 'f':
+			b.WriteByte(0x0c)
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte(0x0b)
+		case 'x', 'X':
+			end := i + 1
+			for end < len(s) && end < i+3 && isHexDigit(s[end]) {
+				end++
+			}
+			if value, err := strconv.ParseUint(s[i+1:end], 16, 8); err == nil {
+				b.WriteByte(byte(value))
+				i = end - 1
+			} else {
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// isFishKeyName reports whether a key uses fish 4 notation such as
+// "ctrl-x,ctrl-e", "alt-left" or "f5". Single characters and escaped
+// sequences are left to escape decoding.
+func isFishKeyName(key string) bool {
+	if len(key) < 2 || strings.Contains(key, "\\") {
+		return false
+	}
+	if strings.ContainsAny(key, "-,") {
+		return true
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+var fishKeyNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"home":      "Home",
+	"end":       "End",
+	"delete":    "Delete",
+	"dc":        "Delete",
+	"backspace": "Backspace",
+	"tab":       "Tab",
+	"btab":      "Shift+Tab",
+	"enter":     "Enter",
+	"escape":    "Esc",
+	"space":     "Space",
+	"insert":    "Insert",
+	"ic":        "Insert",
+	"pageup":    "PageUp",
+	"ppage":     "PageUp",
+	"pagedown":  "PageDown",
+	"npage":     "PageDown",
+}
+
+var fishModifierNames = map[string]string{
+	"ctrl":  "Ctrl+",
+	"alt":   "Alt+",
+	"shift": "Shift+",
+	"super": "Super+",
+}
+
+// displayFishKeyName renders a named fish key ("ctrl-x,ctrl-e", "alt-left",
+// or a terminfo name from `bind -k`) the way the built-in catalog does.
+func displayFishKeyName(name string) string {
+	var chords []string
+
+	for _, chord := range strings.Split(name, ",") {
+		parts := strings.Split(chord, "-")
+		key := parts[len(parts)-1]
+		if key == "" && len(parts) > 1 {
+			// "ctrl--" binds the minus key itself
+			key = "-"
+			parts = parts[:len(parts)-1]
+		}
+
+		var b strings.Builder
+		for _, modifier := range parts[:len(parts)-1] {
+			if prefix, ok := fishModifierNames[modifier]; ok {
+				b.WriteString(prefix)
+			} else {
+				b.WriteString(modifier + "+")
+			}
+		}
+
+		switch {
+		case fishKeyNames[key] != "":
+			b.WriteString(fishKeyNames[key])
+		case len(key) > 1 && key[0] == 'f':
+			b.WriteString(strings.ToUpper(key))
+		case len(key) == 1 && len(parts) > 1:
+			b.WriteString(strings.ToUpper(key))
+		default:
+			b.WriteString(key)
+		}
+		chords = append(chords, b.String())
+	}
+
+	return strings.Join(chords, " ")
+}
+
+// describeFishFunction returns a human-readable description for a fish input
+// function or shell function, falling back to a title-cased version of its name.
+func describeFishFunction(function string) string {
+	if description, ok := fishFunctionDescriptions[function]; ok {
+		return description
+	}
+	return humanizeWidgetName(function)
+}
+
+var fishFunctionDescriptions = map[string]string{
+	"accept-autosuggestion":             "Accept autosuggestion",
+	"backward-char":                     "Back one character",
+	"backward-delete-char":              "Kill one character backward",
+	"backward-kill-line":                "Clear to beginning of line",
+	"backward-kill-path-component":      "Kill path component back",
+	"backward-kill-word":                "Kill word backward",
+	"backward-word":                     "Back one word",
+	"beginning-of-buffer":               "Beginning of buffer",
+	"beginning-of-history":              "First line in history",
+	"beginning-of-line":                 "Beginning of the line",
+	"cancel":                            "Cancel pager or search",
+	"cancel-commandline":                "Cancel current command line",
+	"capitalize-word":                   "Capitalize word",
+	"clear-screen":                      "Clear screen",
+	"complete":                          "Complete command/filename",
+	"complete-and-search":               "Complete and search completions",
+	"delete-char":                       "Delete character under cursor",
+	"delete-or-exit":                    "Delete character or exit",
+	"down-line":                         "Next line in buffer",
+	"down-or-search":                    "Next command in history",
+	"downcase-word":                     "Lowercase word",
+	"edit_command_buffer":               "Edit command in editor",
+	"end-of-buffer":                     "End of buffer",
+	"end-of-history":                    "Last line in history",
+	"end-of-line":                       "End of the line",
+	"execute":                           "Execute command",
+	"expand-abbr":                       "Expand abbreviation",
+	"fish_clipboard_copy":               "Copy line to clipboard",
+	"fish_clipboard_paste":              "Paste from clipboard",
+	"forward-char":                      "Forward one character",
+	"forward-word":                      "Forward one word",
+	"history-pager":                     "Search",
+	"history-search-backward":           "Match prefix in history",
+	"history-search-forward":            "Match prefix in history forward",
+	"history-token-search-backward":     "Extract last word",
+	"history-token-search-forward":      "Next token in history",
+	"kill-line":                         "Kill to end of line",
+	"kill-whole-line":                   "Kill entire line",
+	"kill-word":                         "Kill word forward",
+	"nextd-or-forward-word":             "Forward one word",
+	"prevd-or-backward-word":            "Back one word",
+	"repaint":                           "Redraw prompt",
+	"suppress-autosuggestion":           "Hide autosuggestion",
+	"transpose-chars":                   "Swap cursor with prev character",
+	"transpose-words":                   "Swap cursor with prev word",
+	"undo":                              "Undo",
+	"up-line":                           "Prev line in buffer",
+	"up-or-search":                      "Previous command in history",
+	"upcase-word":                       "Uppercase word",
+	"yank":                              "Paste from Kill Ring",
+	"yank-pop":                          "Cycle Kill Ring",
+	"__fish_list_current_token":         "List files in current token",
+	"__fish_man_page":                   "Show man page for command",
+	"__fish_paginate":                   "Pipe command into pager",
+	"__fish_whatis_current_token":       "Describe current command",
+	"__fish_toggle_comment_commandline": "Toggle comment on line",
+}
+
+// quoteFishKeys renders raw key bytes as an escaped fish `bind` argument,
+// e.g. \cx\ce.
+func quoteFishKeys(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x1b:
+			b.WriteString(`\e`)
+		case c < 0x20:
+			b.WriteString(`\c`)
+			b.WriteString(strings.ToLower(string(c + 0x40)))
+		case c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String()
+}
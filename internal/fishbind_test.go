@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestParseFishBindLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		display string
+		typ     string
+		target  string
+	}{
+		{`bind \cf forward-char`, "Ctrl+F", "widget", "forward-char"},
+		{`bind --preset \ca beginning-of-line`, "Ctrl+A", "widget", "beginning-of-line"},
+		{`bind --preset \cx\ce edit_command_buffer`, "Ctrl+X Ctrl+E", "widget", "edit_command_buffer"},
+		{`bind --preset \ef nextd-or-forward-word`, "Alt+F", "widget", "nextd-or-forward-word"},
+		{`bind --preset \e\[A up-or-search`, "↑", "widget", "up-or-search"},
+		{`bind --preset \e\x7f backward-kill-word`, "Alt+Backspace", "widget", "backward-kill-word"},
+		{`bind --preset -k btab complete-and-search`, "Shift+Tab", "widget", "complete-and-search"},
+		{`bind --preset -k f1 __fish_man_page`, "F1", "widget", "__fish_man_page"},
+		{`bind ctrl-x,ctrl-e edit_command_buffer`, "Ctrl+X Ctrl+E", "widget", "edit_command_buffer"},
+		{`bind alt-left prevd-or-backward-word`, "Alt+←", "widget", "prevd-or-backward-word"},
+		{`bind ctrl-- undo`, "Ctrl+-", "widget", "undo"},
+		{`bind \cg 'git status'`, "Ctrl+G", "command", "git status"},
+		{`bind \e\n 'commandline -i \n' expand-abbr`, "Ctrl+Alt+J", "command", `commandline -i \n; expand-abbr`},
+	}
+
+	for _, test := range tests {
+		entry, ok := parseFishBindLine(test.line)
+		if !ok {
+			t.Errorf("parseFishBindLine(%q) failed to parse", test.line)
+			continue
+		}
+		shortcut := entry.shortcut()
+		if shortcut.Display != test.display {
+			t.Errorf("parseFishBindLine(%q) display = %q, want %q", test.line, shortcut.Display, test.display)
+		}
+		if shortcut.Type != test.typ {
+			t.Errorf("parseFishBindLine(%q) type = %q, want %q", test.line, shortcut.Type, test.typ)
+		}
+		if shortcut.Target != test.target {
+			t.Errorf("parseFishBindLine(%q) target = %q, want %q", test.line, shortcut.Target, test.target)
+		}
+		if shortcut.Description == "" {
+			t.Errorf("parseFishBindLine(%q) has empty description", test.line)
+		}
+	}
+}
+
+func TestParseFishBindOutput(t *testing.T) {
+	output := `bind --preset '' self-insert
+bind --preset \ca beginning-of-line
+bind --preset -M insert \ca beginning-of-line
+bind --preset -M visual -m default \e cancel
+bind \cr history-pager
+`
+
+	shortcuts := parseFishBindOutput(output)
+	if len(shortcuts) != 2 {
+		t.Fatalf("parseFishBindOutput() returned %d shortcuts, want 2", len(shortcuts))
+	}
+}
+
+func TestFishAliasesAndFunctions(t *testing.T) {
+	aliases := parseAliasOutput("alias ll 'ls -lh'\nalias gs 'git status'\n")
+	if len(aliases) != 2 || aliases[0].Display != "ll" || aliases[0].Target != "ls -lh" {
+		t.Errorf("parseAliasOutput() with fish aliases = %+v", aliases)
+	}
+
+	functions := parseFunctionOutput(`# Defined in /home/me/.config/fish/functions/mkcd.fish @ line 1
+function mkcd --description 'Create a directory and cd into it'
+    mkdir -p $argv[1]; and cd $argv[1]
+end
+function gclean
+    # Delete merged branches
+    git branch --merged | xargs git branch -d
+end
+`)
+	if len(functions) != 2 {
+		t.Fatalf("parseFunctionOutput() with fish functions returned %d, want 2", len(functions))
+	}
+	if functions[0].Description != "Create a directory and cd into it" {
+		t.Errorf("mkcd description = %q", functions[0].Description)
+	}
+	if functions[1].Description != "Delete merged branches" {
+		t.Errorf("gclean description = %q", functions[1].Description)
+	}
+}
+
+func TestFishSequenceTarget(t *testing.T) {
+	bindings := `bind --preset \cc cancel-commandline
+bind --preset -M insert \cd delete-or-exit
+bind -M visual \cz 'echo visual'
+bind ctrl-x,ctrl-r 'history merge' repaint
+`
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"C-c", "cancel-commandline"},
+		{"^D", "delete-or-exit"},
+		{"C-x C-r", "history merge; repaint"},
+		{"C-z", ""}, // Only bound in visual mode
+		{"C-s", ""},
+	}
+
+	for _, test := range tests {
+		shortcut := Shortcut{Display: test.target, Type: "sequence", Target: test.target}
+		if result := FishSequenceTarget(shortcut, bindings); result != test.expected {
+			t.Errorf("FishSequenceTarget(%s) = %q, want %q", test.target, result, test.expected)
+		}
+	}
+}
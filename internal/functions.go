@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var functionHeaderPattern = regexp.MustCompile(`^(\S+) \(\)\s*(\{)?$`)
+
+var fishFunctionHeaderPattern = regexp.MustCompile(`^function (\S+)(.*)$`)
+
+// parseFunctionOutput converts shell function data into command shortcuts.
+// It accepts a plain list of names (`print -l ${(k)functions}`), names with
+// the file and line each was defined at, tab-separated, or full definitions
+// (zsh `functions`, bash `declare -f`, fish `functions name...`).
+//
+// zsh and bash print definitions rebuilt from the parsed code, which has no
+// comments left, so their descriptions come from the definition files: the
+// first comment line of the body, or the comment above the definition. fish
+// prints the source as written, so its --description or the first comment
+// line of the body is used. Completion and private functions starting with
+// "_" are skipped.
+func parseFunctionOutput(output string) []Shortcut {
+	var shortcuts []Shortcut
+	seen := make(map[string]bool)
+	sources := make(functionSources)
+
+	add := func(name, description string) {
+		if name == "" || strings.HasPrefix(name, "_") || seen[name] {
+			return
+		}
+		seen[name] = true
+		if description == "" {
+			description = "Shell function " + name
+		}
+		shortcuts = append(shortcuts, Shortcut{
+			Display:     name,
+			Description: description,
+			Type:        "command",
+			Target:      name,
+			IsCustom:    false,
+			Category:    "functions",
+			Source:      "function",
+		})
+	}
+
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+
+		// zsh: name, $functions_source[name]; bash: name, file, line from
+		// `declare -F name` with extdebug
+		if name, location, ok := strings.Cut(line, "\t"); ok {
+			file, number, _ := strings.Cut(location, "\t")
+			start, _ := strconv.Atoi(number)
+			add(name, sources.description(name, file, start))
+			continue
+		}
+
+		if match := functionHeaderPattern.FindStringSubmatch(line); match != nil {
+			// bash puts the opening brace on its own line
+			if match[2] == "" {
+				if i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) != "{" {
+					continue
+				}
+				i++
+			}
+			// The body printed by zsh and bash has no comments left to use
+			for i < len(lines) && strings.TrimRight(lines[i], " \t\r") != "}" {
+				i++
+			}
+			add(match[1], "")
+			continue
+		}
+
+		if match := fishFunctionHeaderPattern.FindStringSubmatch(line); match != nil {
+			var body []string
+			for i++; i < len(lines) && strings.TrimRight(lines[i], " \t\r") != "end"; i++ {
+				body = append(body, lines[i])
+			}
+			description := fishFunctionDescription(match[2])
+			if description == "" {
+				description = functionDescription(body)
+			}
+			add(match[1], description)
+			continue
+		}
+
+		name := strings.TrimSpace(line)
+		if name != "" && !strings.ContainsAny(name, " \t(){}") {
+			add(name, "")
+		}
+	}
+
+	return shortcuts
+}
+
+// fishFunctionDescription extracts the --description option from the rest of
+// a fish `function name ...` header.
+func fishFunctionDescription(options string) string {
+	words := splitShellWords(options)
+	for i, word := range words {
+		switch {
+		case (word == "--description" || word == "-d") && i+1 < len(words):
+			return words[i+1]
+		case strings.HasPrefix(word, "--description="):
+			return strings.TrimPrefix(word, "--description=")
+		}
+	}
+	return ""
+}
+
+// functionSources reads the files functions were defined in, each once.
+type functionSources map[string][]string
+
+func (s functionSources) lines(file string) []string {
+	lines, ok := s[file]
+	if !ok {
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		s[file] = lines
+	}
+	return lines
+}
+
+// description finds the definition of function name in file, on line start
+// when it is known (bash) or else by its name (zsh), and returns the first
+// comment line of its body, or failing that the comment right above it. An
+// autoloaded zsh function is a whole file named after it, without a header.
+func (s functionSources) description(name, file string, start int) string {
+	if file == "" {
+		return ""
+	}
+	lines := s.lines(file)
+	isHeader := functionHeader(name)
+
+	header := -1
+	if start > 0 && start <= len(lines) && isHeader.MatchString(lines[start-1]) {
+		header = start - 1
+	} else {
+		for i, line := range lines {
+			if isHeader.MatchString(line) {
+				header = i
+				break
+			}
+		}
+	}
+	if header < 0 {
+		if filepath.Base(file) == name {
+			return leadingComment(lines)
+		}
+		return ""
+	}
+
+	if comment := leadingComment(lines[header+1:]); comment != "" {
+		return comment
+	}
+	above := header
+	for above > 0 && strings.HasPrefix(strings.TrimSpace(lines[above-1]), "#") {
+		above--
+	}
+	return leadingComment(lines[above:header])
+}
+
+// functionHeader matches the line that starts the definition of function
+// name, as `name() {`, `function name {` or `function name() {`.
+func functionHeader(name string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`^\s*(function\s+` + quoted + `(\s*\(\s*\))?|` + quoted + `\s*\(\s*\))\s*([{(].*)?$`)
+}
+
+// leadingComment returns the text of the first comment line in lines before
+// any code, skipping blank lines, an opening brace and the #! and #compdef
+// markers that start function files.
+func leadingComment(lines []string) string {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || line == "{" || strings.HasPrefix(line, "#!") ||
+			strings.HasPrefix(line, "#compdef") || line == "#autoload":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if comment := strings.TrimSpace(strings.TrimLeft(line, "#")); comment != "" {
+				return comment
+			}
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// functionDescription returns the text of the first comment line in a fish
+// function body.
+func functionDescription(body []string) string {
+	for _, line := range body {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		comment := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if comment == "" || comment == "undefined" {
+			continue
+		}
+		return comment
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFunctionOutputDefinitions(t *testing.T) {
+	// zsh `functions` prints bodies rebuilt from the parsed code, without
+	// comments, except the marker on autoload stubs
+	output := `mkcd () {
+	mkdir -p "$1" && cd "$1"
+}
+_git () {
+	_arguments
+}
+gclean () {
+	git branch --merged | grep -v main | xargs git branch -d
+}
+lazyfn () {
+	# undefined
+	builtin autoload -XU
+}
+`
+
+	shortcuts := parseFunctionOutput(output)
+	if len(shortcuts) != 3 {
+		t.Fatalf("parseFunctionOutput() returned %d shortcuts, want 3", len(shortcuts))
+	}
+
+	for _, shortcut := range shortcuts {
+		if shortcut.Description != "Shell function "+shortcut.Display {
+			t.Errorf("Function %q description = %q, want the default", shortcut.Display, shortcut.Description)
+		}
+		if shortcut.Type != "command" || shortcut.Target != shortcut.Display {
+			t.Errorf("Function %q should be a command targeting itself, got %q %q", shortcut.Display, shortcut.Type, shortcut.Target)
+		}
+	}
+}
+
+func TestParseFunctionOutputSources(t *testing.T) {
+	dir := t.TempDir()
+	rc := filepath.Join(dir, ".zshrc")
+	writeFile(t, rc, `export EDITOR=vim
+
+mkcd() {
+	# Create a directory and cd into it
+	mkdir -p "$1" && cd "$1"
+}
+
+# Delete merged branches
+function gclean {
+	git branch --merged | grep -v main | xargs git branch -d
+}
+
+plain() { ls; }
+`)
+	autoloaded := filepath.Join(dir, "serve")
+	writeFile(t, autoloaded, "#autoload\n\n# Serve the current directory over HTTP\npython3 -m http.server\n")
+
+	output := strings.Join([]string{
+		"mkcd\t" + rc,           // zsh: no line number
+		"gclean\t" + rc + "\t9", // bash: line of the definition
+		"plain\t" + rc,
+		"serve\t" + autoloaded,
+		"gone\t" + filepath.Join(dir, "missing"),
+		"_private\t" + rc,
+	}, "\n")
+
+	expected := map[string]string{
+		"mkcd":   "Create a directory and cd into it",
+		"gclean": "Delete merged branches",
+		"plain":  "Shell function plain",
+		"serve":  "Serve the current directory over HTTP",
+		"gone":   "Shell function gone",
+	}
+
+	shortcuts := parseFunctionOutput(output)
+	if len(shortcuts) != len(expected) {
+		t.Fatalf("parseFunctionOutput() returned %d shortcuts, want %d", len(shortcuts), len(expected))
+	}
+	for _, shortcut := range shortcuts {
+		if description := expected[shortcut.Display]; shortcut.Description != description {
+			t.Errorf("Function %q description = %q, want %q", shortcut.Display, shortcut.Description, description)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestParseFunctionOutputNames(t *testing.T) {
+	shortcuts := parseFunctionOutput("mkcd\n_complete\ngclean\nmkcd\n")
+	if len(shortcuts) != 2 {
+		t.Fatalf("parseFunctionOutput() returned %d shortcuts, want 2", len(shortcuts))
+	}
+	if shortcuts[0].Display != "mkcd" || shortcuts[1].Display != "gclean" {
+		t.Errorf("parseFunctionOutput() names = %q, %q", shortcuts[0].Display, shortcuts[1].Display)
+	}
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"fmt"
+)
+
+// DefaultTriggerKey opens the picker; terminals send Ctrl+_ for Ctrl+/.
+const DefaultTriggerKey = "^_"
+
+// TriggerKey converts a key written in any notation the config accepts into
+// the form the given shell's integration script binds.
+func TriggerKey(shell string, spec string) (string, error) {
+	keys, err := keySequenceBytes(spec)
+	if err != nil {
+		return "", err
+	}
+
+	switch shell {
+	case "zsh":
+		return zshQuoteKeys(keys), nil
+	case "bash":
+		return quoteReadlineString(keys), nil
+	case "fish":
+		return quoteFishKeys(keys), nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s' - only zsh, bash and fish are supported", shell)
+	}
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestTriggerKey(t *testing.T) {
+	tests := []struct {
+		shell    string
+		spec     string
+		expected string
+	}{
+		{"zsh", "^_", `"^_"`},
+		{"zsh", "Ctrl+/", `"^_"`},
+		{"zsh", "C-x C-s", `"^X^S"`},
+		{"zsh", "M-/", `"^[/"`},
+		{"bash", "^_", `"\C-_"`},
+		{"bash", "Ctrl+X Ctrl+S", `"\C-x\C-s"`},
+		{"bash", "Alt+'", `"\e\047"`},
+		{"fish", "^_", `\c_`},
+		{"fish", "C-x C-s", `\cx\cs`},
+		{"fish", "M-/", `\e\x2f`},
+	}
+
+	for _, test := range tests {
+		result, err := TriggerKey(test.shell, test.spec)
+		if err != nil {
+			t.Errorf("TriggerKey(%s, %q) returned error: %v", test.shell, test.spec, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("TriggerKey(%s, %q) = %s, want %s", test.shell, test.spec, result, test.expected)
+		}
+	}
+
+	if _, err := TriggerKey("tcsh", "^_"); err == nil {
+		t.Error("TriggerKey(tcsh) should return an error")
+	}
+	if _, err := TriggerKey("zsh", "Hyper+X"); err == nil {
+		t.Error("TriggerKey(zsh, Hyper+X) should return an error")
+	}
+}
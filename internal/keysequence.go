@@ -0,0 +1,691 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Modifiers records the modifier keys held down for a chord.
+type Modifiers uint8
+
+const (
+	ModCtrl  Modifiers = 1 << iota
+	ModAlt             // Sent as an Esc prefix
+	ModShift           // Only recorded for special keys; letters carry their case
+	ModMeta            // The eighth bit zsh sets for \M- bindings
+)
+
+// Chord is a single key press. Key is either a printable character ("a",
+// "G", "/") or the name of a special key ("Tab", "Enter", "Up", "F5").
+type Chord struct {
+	Key       string
+	Modifiers Modifiers
+}
+
+// KeySequence is a key binding: the chords typed one after the other. It is
+// the canonical form every key notation is parsed into and rendered from.
+type KeySequence []Chord
+
+// singleByteKeys maps the names of special keys that send one byte to it.
+// Backspace is ^?, which is what zsh, readline and fish bind it to; ^H is a
+// separate key with bindings of its own, so it stays Ctrl+H.
+var singleByteKeys = map[string]byte{
+	"Tab":       '\t',
+	"Enter":     '\r',
+	"Esc":       0x1b,
+	"Space":     ' ',
+	"Backspace": 0x7f,
+}
+
+// terminalChordKeys maps the names terminalKey understands to chord keys.
+var terminalChordKeys = map[string]string{
+	"up":          "Up",
+	"down":        "Down",
+	"right":       "Right",
+	"left":        "Left",
+	"home":        "Home",
+	"end":         "End",
+	"insert":      "Insert",
+	"delete":      "Delete",
+	"prior":       "PageUp",
+	"next":        "PageDown",
+	"f1":          "F1",
+	"f2":          "F2",
+	"f3":          "F3",
+	"f4":          "F4",
+	"f5":          "F5",
+	"f6":          "F6",
+	"f7":          "F7",
+	"f8":          "F8",
+	"f9":          "F9",
+	"f10":         "F10",
+	"f11":         "F11",
+	"f12":         "F12",
+	"paste-start": "PasteStart",
+	"paste-end":   "PasteEnd",
+}
+
+// namedKeys maps key names accepted in display and emacs notation, lower
+// cased, to chords.
+var namedKeys = map[string]Chord{
+	"tab":       {Key: "Tab"},
+	"enter":     {Key: "Enter"},
+	"return":    {Key: "Enter"},
+	"ret":       {Key: "Enter"},
+	"lfd":       {Key: "j", Modifiers: ModCtrl},
+	"esc":       {Key: "Esc"},
+	"escape":    {Key: "Esc"},
+	"space":     {Key: "Space"},
+	"spc":       {Key: "Space"},
+	"backspace": {Key: "Backspace"},
+	"del":       {Key: "Backspace"},
+	"backtab":   {Key: "Tab", Modifiers: ModShift},
+	"↑":         {Key: "Up"},
+	"↓":         {Key: "Down"},
+	"→":         {Key: "Right"},
+	"←":         {Key: "Left"},
+}
+
+func init() {
+	for name, key := range terminalChordKeys {
+		namedKeys[name] = Chord{Key: key}
+		namedKeys[strings.ToLower(key)] = Chord{Key: key}
+	}
+}
+
+// arrowGlyphs are the display names of the arrow keys.
+var arrowGlyphs = map[string]string{
+	"Up":    "↑",
+	"Down":  "↓",
+	"Right": "→",
+	"Left":  "←",
+}
+
+// emacsKeyNames are the emacs names of single-byte special keys.
+var emacsKeyNames = map[string]string{
+	"Tab":       "TAB",
+	"Enter":     "RET",
+	"Esc":       "ESC",
+	"Space":     "SPC",
+	"Backspace": "DEL",
+}
+
+// ParseKeySequence parses a key as written in the config or in a sequence
+// target: display notation ("Ctrl+X g", "Alt+F", "↑"), emacs notation
+// ("C-x C-e", "M-<left>", "<f5>", "sudo SPC") or, when it starts with ^ or \
+// and has no spaces, zsh bindkey notation ("^X^E", "^[f", "\M-x").
+func ParseKeySequence(spec string) (KeySequence, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+
+	if (spec[0] == '^' || spec[0] == '\\') && !strings.ContainsAny(spec, " \t") {
+		return parseZshKeys(spec)
+	}
+
+	var sequence KeySequence
+	for _, word := range strings.Fields(spec) {
+		chords, err := parseChords(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key sequence %q: %w", spec, err)
+		}
+		sequence = append(sequence, chords...)
+	}
+	return sequence, nil
+}
+
+// parseZshKeys parses bindkey notation as printed by `bindkey -L`, without
+// the surrounding quotes.
+func parseZshKeys(spec string) (KeySequence, error) {
+	keys, _, err := readBindkeyQuoted(`"`+spec+`"`, 0)
+	if err != nil {
+		return nil, err
+	}
+	return keySequenceFromBytes(keys), nil
+}
+
+// parseReadlineKeys parses inputrc notation as printed by `bind -p`, without
+// the surrounding quotes.
+func parseReadlineKeys(spec string) (KeySequence, error) {
+	keys, _, err := readReadlineQuoted(`"`+spec+`"`, 0)
+	if err != nil {
+		return nil, err
+	}
+	return keySequenceFromBytes(keys), nil
+}
+
+// parseChords parses one word of display or emacs notation: a chord such as
+// "Ctrl+Alt+D", "C-M-d", "^X" or "<f5>", or a run of plain characters such as
+// vi's "gg".
+func parseChords(word string) ([]Chord, error) {
+	var modifiers Modifiers
+	key := word
+
+	for {
+		if len(key) > 2 && key[1] == '-' && strings.ContainsRune("CMScms", rune(key[0])) {
+			switch key[0] {
+			case 'C', 'c':
+				modifiers |= ModCtrl
+			case 'M', 'm':
+				modifiers |= ModAlt
+			default:
+				modifiers |= ModShift
+			}
+			key = key[2:]
+			continue
+		}
+
+		modifier, rest, found := strings.Cut(key, "+")
+		if !found || modifier == "" {
+			break
+		}
+		switch strings.ToLower(modifier) {
+		case "ctrl", "control":
+			modifiers |= ModCtrl
+		case "alt", "meta", "option":
+			modifiers |= ModAlt
+		case "shift":
+			modifiers |= ModShift
+		default:
+			return nil, fmt.Errorf("unknown modifier %q", modifier)
+		}
+		if rest == "" {
+			return nil, fmt.Errorf("missing key after %q", word)
+		}
+		key = rest
+	}
+
+	if len(key) == 2 && key[0] == '^' {
+		modifiers |= ModCtrl
+		key = key[1:]
+	}
+
+	bracketed := len(key) > 2 && key[0] == '<' && key[len(key)-1] == '>'
+	if bracketed {
+		key = key[1 : len(key)-1]
+	}
+
+	var chord Chord
+	if named, ok := namedKeys[strings.ToLower(key)]; ok {
+		chord = named
+	} else if len(key) == 1 && isPrintable(key[0]) {
+		chord = Chord{Key: key}
+	} else if modifiers == 0 && !bracketed {
+		// A run of plain characters, e.g. vi's "gg"
+		var chords []Chord
+		for i := 0; i < len(key); i++ {
+			if !isPrintable(key[i]) {
+				return nil, fmt.Errorf("unknown key %q", key)
+			}
+			chords = append(chords, Chord{Key: key[i : i+1]})
+		}
+		return chords, nil
+	} else {
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+
+	chord.Modifiers |= modifiers
+	if len(chord.Key) == 1 {
+		c := chord.Key[0]
+		if chord.Modifiers&ModShift != 0 && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		} else if chord.Modifiers&(ModCtrl|ModAlt) != 0 && chord.Modifiers&ModShift == 0 && c >= 'A' && c <= 'Z' {
+			// "Alt+F" is written in upper case but means a lower case f
+			c += 'a' - 'A'
+		}
+		chord.Key = string(c)
+		chord.Modifiers &^= ModShift
+	}
+
+	if _, err := chord.bytes(); err != nil {
+		return nil, err
+	}
+	return []Chord{chord}, nil
+}
+
+func isPrintable(c byte) bool {
+	return c > ' ' && c < 0x7f
+}
+
+// keySequenceFromBytes decodes the raw bytes a terminal sends, e.g. "\x18\x05"
+// is Ctrl+X Ctrl+E and "\x1bf" is Alt+F. Escape sequences with no known name
+// are kept as Esc followed by their characters.
+func keySequenceFromBytes(keys string) KeySequence {
+	var sequence KeySequence
+
+	for i := 0; i < len(keys); {
+		if keys[i] == 0x1b && i+1 < len(keys) {
+			if chord, n, ok := terminalChord(keys[i:]); ok {
+				sequence = append(sequence, chord)
+				i += n
+				continue
+			}
+			if n := escapeSequenceLength(keys[i:]); n > 0 {
+				for j := i; j < i+n; j++ {
+					sequence = append(sequence, byteChord(keys[j]))
+				}
+				i += n
+				continue
+			}
+			if chord, n, ok := terminalChord(keys[i+1:]); ok {
+				chord.Modifiers |= ModAlt
+				sequence = append(sequence, chord)
+				i += 1 + n
+				continue
+			}
+			chord := byteChord(keys[i+1])
+			chord.Modifiers |= ModAlt
+			sequence = append(sequence, chord)
+			i += 2
+			continue
+		}
+
+		sequence = append(sequence, byteChord(keys[i]))
+		i++
+	}
+
+	return sequence
+}
+
+// byteChord decodes a single byte.
+func byteChord(c byte) Chord {
+	var meta Modifiers
+	if c >= 0x80 {
+		meta = ModMeta
+		c &= 0x7f
+	}
+
+	for name, b := range singleByteKeys {
+		if b == c {
+			return Chord{Key: name, Modifiers: meta}
+		}
+	}
+	if c < 0x20 {
+		return Chord{Key: strings.ToLower(string(c + 0x40)), Modifiers: ModCtrl | meta}
+	}
+	return Chord{Key: string(c), Modifiers: meta}
+}
+
+// escapeSequenceLength returns the length of the CSI or SS3 sequence at the
+// start of keys, or 0 if keys does not start with one.
+func escapeSequenceLength(keys string) int {
+	if len(keys) < 3 || keys[0] != 0x1b || (keys[1] != '[' && keys[1] != 'O') {
+		return 0
+	}
+
+	end := 2
+	if keys[1] == '[' {
+		for end < len(keys) && (keys[end] < 0x40 || keys[end] > 0x7e) {
+			end++
+		}
+	}
+	if end >= len(keys) {
+		return 0
+	}
+	return end + 1
+}
+
+// terminalChord decodes the escape sequence at the start of keys if it is a
+// known terminal key, possibly with xterm modifiers such as "\e[1;5A" for
+// Ctrl+↑. It returns the chord and the number of bytes consumed.
+func terminalChord(keys string) (Chord, int, bool) {
+	n := escapeSequenceLength(keys)
+	if n == 0 {
+		return Chord{}, 0, false
+	}
+	sequence := keys[:n]
+
+	if chord, ok := terminalNameChord(sequence); ok {
+		return chord, n, true
+	}
+	if name, code, ok := terminfoModifiedKeyName(sequence); ok {
+		return Chord{Key: terminalChordKeys[name], Modifiers: xtermModifiers(code)}, n, true
+	}
+
+	// xterm sends modified keys as "\e[<params>;<modifier><final>"
+	params, modifier, found := strings.Cut(sequence[2:n-1], ";")
+	code, err := strconv.Atoi(modifier)
+	if !found || err != nil || code < 2 || keys[1] != '[' {
+		return Chord{}, 0, false
+	}
+
+	final := sequence[n-1:]
+	candidates := []string{"\x1b[" + params + final}
+	if params == "1" {
+		candidates = []string{"\x1b[" + final, "\x1bO" + final}
+	}
+	for _, candidate := range candidates {
+		if chord, ok := terminalNameChord(candidate); ok {
+			chord.Modifiers |= xtermModifiers(code)
+			return chord, n, true
+		}
+	}
+	return Chord{}, 0, false
+}
+
+// xtermModifiers decodes xterm's modifier parameter, one more than a bit
+// mask of Shift, Alt and Ctrl.
+func xtermModifiers(code int) Modifiers {
+	var modifiers Modifiers
+	bits := code - 1
+	if bits&1 != 0 {
+		modifiers |= ModShift
+	}
+	if bits&2 != 0 {
+		modifiers |= ModAlt
+	}
+	if bits&4 != 0 {
+		modifiers |= ModCtrl
+	}
+	return modifiers
+}
+
+// terminalNameChord looks up an unmodified terminal key sequence.
+func terminalNameChord(sequence string) (Chord, bool) {
+	name, ok := terminalKeyName(sequence)
+	if !ok {
+		return Chord{}, false
+	}
+	if name == "backtab" {
+		return Chord{Key: "Tab", Modifiers: ModShift}, true
+	}
+	return Chord{Key: terminalChordKeys[name]}, true
+}
+
+// terminalName returns the terminalKey name for a chord key, if it has one.
+func terminalName(key string) (string, bool) {
+	for name, chordKey := range terminalChordKeys {
+		if chordKey == key {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// bytes returns what the terminal sends for the chord.
+func (c Chord) bytes() (string, error) {
+	modifiers := c.Modifiers
+
+	var keys string
+	name, isTerminalKey := terminalName(c.Key)
+	if c.Key == "Tab" && modifiers&ModShift != 0 {
+		keys, _ = terminalKey("backtab")
+		modifiers &^= ModShift
+	} else if b, ok := singleByteKeys[c.Key]; ok {
+		keys = string(b)
+	} else if isTerminalKey {
+		keys, _ = terminalKey(name)
+	} else if len(c.Key) == 1 {
+		keys = c.Key
+	} else {
+		return "", fmt.Errorf("unknown key %q", c.Key)
+	}
+
+	if len(keys) == 1 {
+		b := keys[0]
+		if modifiers&ModCtrl != 0 {
+			switch {
+			case b == '?':
+				b = 0x7f
+			case b == '/':
+				// Terminals send Ctrl+_ for Ctrl+/
+				b = 0x1f
+			case b == ' ':
+				b = 0x00
+			case b >= '@' && b <= '~':
+				b &= 0x1f
+			default:
+				return "", fmt.Errorf("%q has no control character", c.Key)
+			}
+		}
+		if modifiers&ModMeta != 0 {
+			b |= 0x80
+		}
+		keys = string([]byte{b})
+	} else if modifiers&(ModCtrl|ModShift) != 0 {
+		code := 1
+		if modifiers&ModShift != 0 {
+			code += 1
+		}
+		if modifiers&ModCtrl != 0 {
+			code += 4
+		}
+		modified, ok := terminfoModifiedKey(name, code)
+		if !ok {
+			modified, ok = xtermModifiedKey(keys, modifiers&ModCtrl != 0, modifiers&ModShift != 0)
+		}
+		if !ok {
+			return "", fmt.Errorf("modifiers on %q are not supported", c.Key)
+		}
+		keys = modified
+	}
+
+	if modifiers&ModAlt != 0 {
+		keys = "\x1b" + keys
+	}
+	return keys, nil
+}
+
+// isPlain reports whether the chord types a printable character.
+func (c Chord) isPlain() bool {
+	return c.Modifiers == 0 && len(c.Key) == 1 && isPrintable(c.Key[0])
+}
+
+// shifted reports whether the chord needs Shift spelled out: for special
+// keys, and for capital letters typed with another modifier.
+func (c Chord) shifted() bool {
+	if c.Modifiers&ModShift != 0 {
+		return true
+	}
+	return c.Modifiers != 0 && len(c.Key) == 1 && c.Key[0] >= 'A' && c.Key[0] <= 'Z'
+}
+
+// Bytes returns the raw bytes the terminal sends for the sequence.
+func (s KeySequence) Bytes() string {
+	var b strings.Builder
+	for _, chord := range s {
+		keys, _ := chord.bytes()
+		b.WriteString(keys)
+	}
+	return b.String()
+}
+
+// Display renders the sequence the way the built-in catalog writes keys,
+// e.g. "Ctrl+X Ctrl+E", "Alt+F" or "↑". Runs of plain characters such as
+// vi's "gg" are kept together.
+func (s KeySequence) Display() string {
+	return s.render(Chord.display, " ")
+}
+
+// Emacs renders the sequence in emacs notation, the format sequence targets
+// use, e.g. "C-x C-e", "M-f", "<f5>" or "git SPC status RET".
+func (s KeySequence) Emacs() string {
+	return s.render(Chord.emacs, " ")
+}
+
+// Zsh renders the sequence in bindkey notation, e.g. "^X^E" or "^[[3~".
+func (s KeySequence) Zsh() string {
+	return bindkeyNotation(s.Bytes())
+}
+
+// Readline renders the sequence in inputrc notation, e.g. "\C-x\C-e" or
+// "\e[3~".
+func (s KeySequence) Readline() string {
+	return readlineNotation(s.Bytes())
+}
+
+// KeyStyles are the notations the [ui] key_style setting accepts.
+var KeyStyles = []string{"default", "emacs", "symbols", "verbose", "zsh"}
+
+// Format renders the sequence in one of KeyStyles: "default" is Display,
+// "emacs" is C-x C-e, "symbols" is ⌃X ⌃E, "verbose" is Control+X, Control+E
+// and "zsh" is ^X^E. Unknown styles fall back to the default.
+func (s KeySequence) Format(style string) string {
+	switch style {
+	case "emacs":
+		return s.Emacs()
+	case "symbols":
+		return s.render(Chord.symbols, " ")
+	case "verbose":
+		return s.render(Chord.verbose, ", ")
+	case "zsh":
+		return s.Zsh()
+	default:
+		return s.Display()
+	}
+}
+
+// render joins the chords rendered by name with sep, keeping runs of plain
+// characters together as one word.
+func (s KeySequence) render(name func(Chord) string, sep string) string {
+	var words []string
+	plainRun := false
+
+	for _, chord := range s {
+		plain := chord.isPlain()
+		if plainRun && plain {
+			words[len(words)-1] += chord.Key
+		} else {
+			words = append(words, name(chord))
+		}
+		plainRun = plain
+	}
+
+	return strings.Join(words, sep)
+}
+
+func (c Chord) display() string {
+	var b strings.Builder
+	if c.Modifiers&ModMeta != 0 {
+		b.WriteString("Meta+")
+	}
+	if c.Modifiers&ModCtrl != 0 {
+		b.WriteString("Ctrl+")
+	}
+	if c.Modifiers&ModAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if c.shifted() {
+		b.WriteString("Shift+")
+	}
+
+	switch {
+	case arrowGlyphs[c.Key] != "":
+		b.WriteString(arrowGlyphs[c.Key])
+	case c.Modifiers != 0 && len(c.Key) == 1:
+		b.WriteString(strings.ToUpper(c.Key))
+	default:
+		b.WriteString(c.Key)
+	}
+	return b.String()
+}
+
+func (c Chord) emacs() string {
+	if c.Key == "j" && c.Modifiers == ModCtrl {
+		return "LFD"
+	}
+
+	var b strings.Builder
+	if c.Modifiers&ModCtrl != 0 {
+		b.WriteString("C-")
+	}
+	if c.Modifiers&(ModAlt|ModMeta) != 0 {
+		b.WriteString("M-")
+	}
+
+	if c.Key == "Tab" && c.Modifiers&ModShift != 0 {
+		b.WriteString("<backtab>")
+		return b.String()
+	}
+	if c.shifted() {
+		b.WriteString("S-")
+	}
+
+	if name, ok := emacsKeyNames[c.Key]; ok {
+		b.WriteString(name)
+	} else if name, ok := terminalName(c.Key); ok {
+		b.WriteString("<" + name + ">")
+	} else if c.Modifiers != 0 {
+		b.WriteString(strings.ToLower(c.Key))
+	} else {
+		b.WriteString(c.Key)
+	}
+	return b.String()
+}
+
+// keySymbols are the Mac-style symbols for special keys.
+var keySymbols = map[string]string{
+	"Tab":       "⇥",
+	"Enter":     "↩",
+	"Esc":       "⎋",
+	"Space":     "␣",
+	"Backspace": "⌫",
+	"Delete":    "⌦",
+	"Home":      "↖",
+	"End":       "↘",
+	"PageUp":    "⇞",
+	"PageDown":  "⇟",
+}
+
+func (c Chord) symbols() string {
+	var b strings.Builder
+	if c.Modifiers&ModCtrl != 0 {
+		b.WriteString("⌃")
+	}
+	if c.Modifiers&(ModAlt|ModMeta) != 0 {
+		b.WriteString("⌥")
+	}
+	if c.shifted() {
+		b.WriteString("⇧")
+	}
+
+	switch {
+	case keySymbols[c.Key] != "":
+		b.WriteString(keySymbols[c.Key])
+	case arrowGlyphs[c.Key] != "":
+		b.WriteString(arrowGlyphs[c.Key])
+	default:
+		b.WriteString(strings.ToUpper(c.Key))
+	}
+	return b.String()
+}
+
+// verboseKeyNames spell out special keys whose display name is abbreviated.
+var verboseKeyNames = map[string]string{
+	"Esc":      "Escape",
+	"Up":       "Up Arrow",
+	"Down":     "Down Arrow",
+	"Right":    "Right Arrow",
+	"Left":     "Left Arrow",
+	"PageUp":   "Page Up",
+	"PageDown": "Page Down",
+}
+
+// verbose renders a chord with its names spelled out.
+func (c Chord) verbose() string {
+	var b strings.Builder
+	if c.Modifiers&ModMeta != 0 {
+		b.WriteString("Meta+")
+	}
+	if c.Modifiers&ModCtrl != 0 {
+		b.WriteString("Control+")
+	}
+	if c.Modifiers&ModAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if c.shifted() {
+		b.WriteString("Shift+")
+	}
+
+	switch {
+	case verboseKeyNames[c.Key] != "":
+		b.WriteString(verboseKeyNames[c.Key])
+	case c.Modifiers != 0 && len(c.Key) == 1:
+		b.WriteString(strings.ToUpper(c.Key))
+	default:
+		b.WriteString(c.Key)
+	}
+	return b.String()
+}
@@ -0,0 +1,223 @@
+package internal
+
+import "testing"
+
+func TestParseZshKeys(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		spec     string
+		display  string
+		emacs    string
+		readline string
+	}{
+		{"^X^E", "Ctrl+X Ctrl+E", "C-x C-e", `\C-x\C-e`},
+		{"^[f", "Alt+F", "M-f", `\ef`},
+		{"^[F", "Alt+Shift+F", "M-S-f", `\eF`},
+		{"^[[3~", "Delete", "<delete>", `\e[3~`},
+		{"^[OA", "↑", "<up>", `\e[A`},
+		{"^[[1;5A", "Ctrl+↑", "C-<up>", `\e[1;5A`},
+		{"^[[3;5~", "Ctrl+Delete", "C-<delete>", `\e[3;5~`},
+		{"^[^[[D", "Alt+←", "M-<left>", `\e\e[D`},
+		{"^[[Z", "Shift+Tab", "<backtab>", `\e[Z`},
+		{"^[[200~", "PasteStart", "<paste-start>", `\e[200~`},
+		{"^[[99~", "Esc [99~", "ESC [99~", `\e[99~`},
+		{"^Xg", "Ctrl+X g", "C-x g", `\C-xg`},
+		{"^?^I^M^J", "Backspace Tab Enter Ctrl+J", "DEL TAB RET LFD", `\C-?\C-i\C-m\C-j`},
+		{`\M-^@`, "Meta+Ctrl+@", "C-M-@", `\200`},
+	}
+
+	for _, test := range tests {
+		sequence, err := parseZshKeys(test.spec)
+		if err != nil {
+			t.Errorf("parseZshKeys(%q) returned error: %v", test.spec, err)
+			continue
+		}
+		if got := sequence.Display(); got != test.display {
+			t.Errorf("parseZshKeys(%q).Display() = %q, want %q", test.spec, got, test.display)
+		}
+		if got := sequence.Emacs(); got != test.emacs {
+			t.Errorf("parseZshKeys(%q).Emacs() = %q, want %q", test.spec, got, test.emacs)
+		}
+		if got := sequence.Readline(); got != test.readline {
+			t.Errorf("parseZshKeys(%q).Readline() = %q, want %q", test.spec, got, test.readline)
+		}
+		if got := sequence.Zsh(); got != test.spec && test.spec != "^[OA" {
+			t.Errorf("parseZshKeys(%q).Zsh() = %q", test.spec, got)
+		}
+	}
+}
+
+func TestParseReadlineKeys(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		spec    string
+		display string
+		zsh     string
+	}{
+		{`\C-x\C-e`, "Ctrl+X Ctrl+E", "^X^E"},
+		{`\ef`, "Alt+F", "^[f"},
+		{`\M-.`, "Alt+.", "^[."},
+		{`\e[3~`, "Delete", "^[[3~"},
+		{`\C-?`, "Backspace", "^?"},
+	}
+
+	for _, test := range tests {
+		sequence, err := parseReadlineKeys(test.spec)
+		if err != nil {
+			t.Errorf("parseReadlineKeys(%q) returned error: %v", test.spec, err)
+			continue
+		}
+		if got := sequence.Display(); got != test.display {
+			t.Errorf("parseReadlineKeys(%q).Display() = %q, want %q", test.spec, got, test.display)
+		}
+		if got := sequence.Zsh(); got != test.zsh {
+			t.Errorf("parseReadlineKeys(%q).Zsh() = %q, want %q", test.spec, got, test.zsh)
+		}
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		spec    string
+		display string
+		emacs   string
+	}{
+		{"C-x C-e", "Ctrl+X Ctrl+E", "C-x C-e"},
+		{"ctrl+x ctrl+e", "Ctrl+X Ctrl+E", "C-x C-e"},
+		{"Alt+F", "Alt+F", "M-f"},
+		{"M-F", "Alt+F", "M-f"},
+		{"Alt+Shift+F", "Alt+Shift+F", "M-S-f"},
+		{"Ctrl+Shift+A", "Ctrl+Shift+A", "C-S-a"},
+		{"shift+tab", "Shift+Tab", "<backtab>"},
+		{"<f5>", "F5", "<f5>"},
+		{"PageUp", "PageUp", "<prior>"},
+		{"S-<up>", "Shift+↑", "S-<up>"},
+		{"gg", "gg", "gg"},
+		{"git SPC status RET", "git Space status Enter", "git SPC status RET"},
+	}
+
+	for _, test := range tests {
+		sequence, err := ParseKeySequence(test.spec)
+		if err != nil {
+			t.Errorf("ParseKeySequence(%q) returned error: %v", test.spec, err)
+			continue
+		}
+		if got := sequence.Display(); got != test.display {
+			t.Errorf("ParseKeySequence(%q).Display() = %q, want %q", test.spec, got, test.display)
+		}
+		if got := sequence.Emacs(); got != test.emacs {
+			t.Errorf("ParseKeySequence(%q).Emacs() = %q, want %q", test.spec, got, test.emacs)
+		}
+	}
+
+	for _, spec := range []string{"ctrl+", "Alt+Hyper", "Ctrl+<f99>"} {
+		if _, err := ParseKeySequence(spec); err == nil {
+			t.Errorf("ParseKeySequence(%q) should return an error", spec)
+		}
+	}
+}
+
+func TestKeySequenceRoundTrip(t *testing.T) {
+	withXtermKeys(t)
+
+	keys := []string{
+		"\x01", "\x18\x05", "\x1bf", "\x1bF", "\x1b\x04", "\x1b[A", "gg", "\x18g",
+		"\x1b[1;5A", "\x1b\x1b[D", "\x1b[Z", "git status\r", "\x7f\t", "\x1b[15~",
+	}
+
+	for _, raw := range keys {
+		sequence := keySequenceFromBytes(raw)
+		for _, notation := range []string{sequence.Display(), sequence.Emacs()} {
+			parsed, err := ParseKeySequence(notation)
+			if err != nil || parsed.Bytes() != raw {
+				t.Errorf("ParseKeySequence(%q) = %q, %v, want %q", notation, parsed.Bytes(), err, raw)
+			}
+		}
+		parsed, err := parseZshKeys(sequence.Zsh())
+		if err != nil || parsed.Bytes() != raw {
+			t.Errorf("parseZshKeys(%q) = %q, %v, want %q", sequence.Zsh(), parsed.Bytes(), err, raw)
+		}
+		parsed, err = parseReadlineKeys(sequence.Readline())
+		if err != nil || parsed.Bytes() != raw {
+			t.Errorf("parseReadlineKeys(%q) = %q, %v, want %q", sequence.Readline(), parsed.Bytes(), err, raw)
+		}
+	}
+}
+
+func TestKeySequenceBytesRoundTrip(t *testing.T) {
+	withXtermKeys(t)
+
+	for _, keys := range []string{"\x01", "\x18\x05", "\x1bf", "\x1bF", "\x1b\x04", "\x1b[A", "gg", "\x18g"} {
+		display := keySequenceFromBytes(keys).Display()
+		result, err := keySequenceBytes(display)
+		if err != nil || result != keys {
+			t.Errorf("keySequenceBytes(Display(%q) = %q) = %q, %v", keys, display, result, err)
+		}
+	}
+}
+
+func TestKeySequenceEmacs(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"\x18\x05", "C-x C-e"},
+		{"\x1bf", "M-f"},
+		{"git status\r", "git SPC status RET"},
+		{"\x1b[A", "<up>"},
+		{"\x1bOA", "<up>"},
+		{"\x1b[15~", "<f5>"},
+		{"\x7f\t", "DEL TAB"},
+	}
+
+	for _, test := range tests {
+		result := keySequenceFromBytes(test.keys).Emacs()
+		if result != test.expected {
+			t.Errorf("keySequenceFromBytes(%q).Emacs() = %q, want %q", test.keys, result, test.expected)
+		}
+		if keys, err := keySequenceBytes(result); err != nil || (keys != test.keys && test.keys != "\x1bOA") {
+			t.Errorf("keySequenceBytes(%q) = %q, %v, want %q", result, keys, err, test.keys)
+		}
+	}
+}
+
+func TestKeySequenceFormat(t *testing.T) {
+	withXtermKeys(t)
+
+	tests := []struct {
+		spec     string
+		style    string
+		expected string
+	}{
+		{"C-x C-e", "default", "Ctrl+X Ctrl+E"},
+		{"C-x C-e", "", "Ctrl+X Ctrl+E"},
+		{"C-x C-e", "emacs", "C-x C-e"},
+		{"C-x C-e", "symbols", "⌃X ⌃E"},
+		{"C-x C-e", "verbose", "Control+X, Control+E"},
+		{"C-x C-e", "zsh", "^X^E"},
+		{"Alt+F", "symbols", "⌥F"},
+		{"Alt+Shift+F", "symbols", "⌥⇧F"},
+		{"Shift+Tab", "symbols", "⇧⇥"},
+		{"↑", "verbose", "Up Arrow"},
+		{"Esc PageUp", "verbose", "Escape, Page Up"},
+		{"gg", "verbose", "gg"},
+		{"Delete", "zsh", "^[[3~"},
+		{"C-x C-e", "unknown", "Ctrl+X Ctrl+E"},
+	}
+
+	for _, test := range tests {
+		sequence, err := ParseKeySequence(test.spec)
+		if err != nil {
+			t.Fatalf("ParseKeySequence(%q) returned error: %v", test.spec, err)
+		}
+		if got := sequence.Format(test.style); got != test.expected {
+			t.Errorf("ParseKeySequence(%q).Format(%q) = %q, want %q", test.spec, test.style, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Card is the spaced-repetition state of one shortcut in learn mode,
+// scheduled with the SM-2 algorithm.
+type Card struct {
+	EaseFactor  float64   `json:"ease_factor"`
+	Interval    int       `json:"interval"`    // Days between the last review and Due
+	Repetitions int       `json:"repetitions"` // Correct reviews in a row
+	Due         time.Time `json:"due"`
+	Reviews     int       `json:"reviews"`
+	Lapses      int       `json:"lapses"` // Times a learned card was forgotten
+}
+
+// defaultEaseFactor is the ease SM-2 gives a card never reviewed.
+const defaultEaseFactor = 2.5
+
+// LearnProgress holds the cards reviewed so far, keyed like the usage store.
+type LearnProgress struct {
+	Cards map[string]Card `json:"cards"`
+}
+
+// LearnPath returns the location of the saved learn mode progress.
+func LearnPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "shortcutter", "learn.json"), nil
+}
+
+// LoadLearnProgress reads the saved progress, which is empty before the
+// first session.
+func LoadLearnProgress() (*LearnProgress, error) {
+	progress := &LearnProgress{Cards: make(map[string]Card)}
+
+	path, err := LearnPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+	if progress.Cards == nil {
+		progress.Cards = make(map[string]Card)
+	}
+	return progress, nil
+}
+
+// Save writes the progress.
+func (p *LearnProgress) Save() error {
+	path, err := LearnPath()
+	if err != nil {
+		return err
+	}
+	return writeDataFile(path, p)
+}
+
+// review grades the card with an SM-2 quality from 0 (blackout) to 5
+// (perfect) and schedules its next review.
+func (c Card) review(quality int, now time.Time) Card {
+	if c.EaseFactor == 0 {
+		c.EaseFactor = defaultEaseFactor
+	}
+	c.Reviews++
+
+	if quality < 3 {
+		if c.Repetitions > 0 {
+			c.Lapses++
+		}
+		c.Repetitions = 0
+		c.Interval = 1
+	} else {
+		switch c.Repetitions {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.EaseFactor))
+		}
+		c.Repetitions++
+	}
+
+	miss := float64(5 - quality)
+	c.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if c.EaseFactor < 1.3 {
+		c.EaseFactor = 1.3
+	}
+
+	c.Due = now.AddDate(0, 0, c.Interval)
+	return c
+}
+
+// answerQuality grades an answer for review: wrong answers score 1, right
+// ones 5, 4 or 3 depending on how long they took.
+func answerQuality(correct bool, elapsed time.Duration) int {
+	switch {
+	case !correct:
+		return 1
+	case elapsed < 3*time.Second:
+		return 5
+	case elapsed < 8*time.Second:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// learnable reports whether a shortcut can be quizzed: it needs keys the
+// terminal can send, other than Ctrl+C, which always ends the session.
+func learnable(shortcut Shortcut) bool {
+	if len(shortcut.Keys) == 0 {
+		return false
+	}
+	for _, chord := range shortcut.Keys {
+		keys, err := chord.bytes()
+		if err != nil || keys == "\x03" || chord.Modifiers&ModMeta != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// session picks the shortcuts to quiz: every card that is due, the most
+// overdue first, then up to newCards shortcuts never reviewed.
+func (p *LearnProgress) session(shortcuts []Shortcut, newCards int, now time.Time) []Shortcut {
+	var due, fresh []Shortcut
+	seen := make(map[string]bool)
+	for _, shortcut := range shortcuts {
+		key := usageKey(shortcut)
+		if seen[key] || !learnable(shortcut) {
+			continue
+		}
+		seen[key] = true
+
+		card, ok := p.Cards[key]
+		if !ok {
+			if len(fresh) < newCards {
+				fresh = append(fresh, shortcut)
+			}
+		} else if !card.Due.After(now) {
+			due = append(due, shortcut)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return p.Cards[usageKey(due[i])].Due.Before(p.Cards[usageKey(due[j])].Due)
+	})
+	return append(due, fresh...)
+}
+
+// LearnSummary is the outcome of a learn session.
+type LearnSummary struct {
+	Reviewed int // Cards answered, retries not counted
+	Correct  int // Cards answered right the first time
+	Left     int // Cards still queued when the session was ended
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCardReview(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	var card Card
+	expectedIntervals := []int{1, 6, 16}
+	for i, expected := range expectedIntervals {
+		card = card.review(5, now)
+		if card.Interval != expected {
+			t.Errorf("Review %d: interval %d, want %d", i+1, card.Interval, expected)
+		}
+	}
+	if card.Repetitions != 3 || card.Reviews != 3 {
+		t.Errorf("After three reviews: repetitions %d, reviews %d", card.Repetitions, card.Reviews)
+	}
+	if !card.Due.Equal(now.AddDate(0, 0, 16)) {
+		t.Errorf("Due: got %v, want 16 days from now", card.Due)
+	}
+	if card.EaseFactor <= defaultEaseFactor {
+		t.Errorf("Perfect answers should raise the ease factor, got %v", card.EaseFactor)
+	}
+
+	card = card.review(1, now)
+	if card.Interval != 1 || card.Repetitions != 0 || card.Lapses != 1 {
+		t.Errorf("Forgotten card: interval %d, repetitions %d, lapses %d", card.Interval, card.Repetitions, card.Lapses)
+	}
+
+	for i := 0; i < 10; i++ {
+		card = card.review(0, now)
+	}
+	if card.EaseFactor != 1.3 {
+		t.Errorf("Ease factor should not drop below 1.3, got %v", card.EaseFactor)
+	}
+}
+
+func TestAnswerQuality(t *testing.T) {
+	tests := []struct {
+		correct  bool
+		elapsed  time.Duration
+		expected int
+	}{
+		{true, time.Second, 5},
+		{true, 5 * time.Second, 4},
+		{true, time.Minute, 3},
+		{false, time.Second, 1},
+	}
+
+	for _, test := range tests {
+		if got := answerQuality(test.correct, test.elapsed); got != test.expected {
+			t.Errorf("answerQuality(%v, %v) = %d, want %d", test.correct, test.elapsed, got, test.expected)
+		}
+	}
+}
+
+func TestLearnSession(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	keys := func(spec string) KeySequence {
+		sequence, err := ParseKeySequence(spec)
+		if err != nil {
+			t.Fatalf("ParseKeySequence(%q) returned error: %v", spec, err)
+		}
+		return sequence
+	}
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+A", Keys: keys("Ctrl+A"), Keymap: "emacs"},
+		{Display: "Ctrl+E", Keys: keys("Ctrl+E"), Keymap: "emacs"},
+		{Display: "Ctrl+R", Keys: keys("Ctrl+R"), Keymap: "emacs"},
+		{Display: "Ctrl+C", Keys: keys("Ctrl+C"), Keymap: "emacs"},
+		{Display: "gs", Type: "command", Target: "git status"},
+		{Display: "Ctrl+K", Keys: keys("Ctrl+K"), Keymap: "emacs"},
+		{Display: "Ctrl+W", Keys: keys("Ctrl+W"), Keymap: "emacs"},
+	}
+	progress := &LearnProgress{Cards: map[string]Card{
+		usageKey(shortcuts[0]): {Interval: 6, Due: now.AddDate(0, 0, -1)},
+		usageKey(shortcuts[1]): {Interval: 6, Due: now.AddDate(0, 0, 3)},
+		usageKey(shortcuts[2]): {Interval: 1, Due: now.AddDate(0, 0, -4)},
+	}}
+
+	var got []string
+	for _, shortcut := range progress.session(shortcuts, 1, now) {
+		got = append(got, shortcut.Display)
+	}
+	expected := []string{"Ctrl+R", "Ctrl+A", "Ctrl+K"}
+	if len(got) != len(expected) {
+		t.Fatalf("session() = %v, want %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("session() = %v, want %v", got, expected)
+			break
+		}
+	}
+}
+
+func TestLearnProgressRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	progress, err := LoadLearnProgress()
+	if err != nil {
+		t.Fatalf("LoadLearnProgress() without saved progress returned error: %v", err)
+	}
+	progress.Cards["Ctrl+A"] = Card{}.review(5, time.Now())
+	if err := progress.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	progress, err = LoadLearnProgress()
+	if err != nil {
+		t.Fatalf("LoadLearnProgress() returned error: %v", err)
+	}
+	if card := progress.Cards["Ctrl+A"]; card.Repetitions != 1 || card.Interval != 1 {
+		t.Errorf("Saved card: got %+v", card)
+	}
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProtocolVersion is bumped whenever the handoff fields change incompatibly.
+const ProtocolVersion = 1
+
+// Exit codes used to tell the shell integration what happened.
+const (
+	ExitSelected  = 0
+	ExitCancelled = 1
+	ExitError     = 2
+)
+
+// Handoff is the result passed from the picker back to the shell widget.
+type Handoff struct {
+	Key    string // "enter" or "tab"
+	Type   string // Shortcut type
+	Target string // Shortcut target
+	Keymap string // Keymap the shortcut belongs to, if any
+	Keys   string // Raw keys bound to a widget in the active keymap, if known
+}
+
+// NewHandoff builds the handoff for a selected shortcut.
+func NewHandoff(shortcut Shortcut, key string) Handoff {
+	return Handoff{
+		Key:    key,
+		Type:   shortcut.Type,
+		Target: shortcut.Target,
+		Keymap: shortcut.Keymap,
+	}
+}
+
+// ResolveKeys returns the raw keys the integration replays for a shortcut:
+// the key sequence a widget is bound to in keymap, looked up in the live
+// `bindkey -L` dump, or the parsed target of a sequence. Other shortcuts, and
+// widgets not bound in keymap, have no keys.
+func ResolveKeys(shortcut Shortcut, bindings string, keymap string) (string, error) {
+	switch shortcut.Type {
+	case "widget":
+		keys, _ := WidgetKeys(bindings, shortcut.Target, keymap)
+		return keys, nil
+	case "sequence":
+		return keySequenceBytes(shortcut.Target)
+	default:
+		return "", nil
+	}
+}
+
+// fields returns the handoff as ordered name/value pairs, protocol version first.
+func (h Handoff) fields() [][2]string {
+	return [][2]string{
+		{"SHORTCUTTER_PROTOCOL", fmt.Sprint(ProtocolVersion)},
+		{"SHORTCUTTER_KEY", h.Key},
+		{"SHORTCUTTER_TYPE", h.Type},
+		{"SHORTCUTTER_TARGET", h.Target},
+		{"SHORTCUTTER_KEYMAP", h.Keymap},
+		{"SHORTCUTTER_KEYS", echoEscape(h.Keys)},
+	}
+}
+
+// handoffFormats maps each output dialect to the line template for one field.
+var handoffFormats = map[string]string{
+	"sh":   "%s=%s\n",
+	"fish": "set -g %s %s\n",
+}
+
+// IsHandoffFormat reports whether dialect is a known output format.
+func IsHandoffFormat(dialect string) bool {
+	_, ok := handoffFormats[dialect]
+	return ok
+}
+
+// Format renders the handoff for the given shell dialect so the integration
+// can eval it: "sh" emits NAME='value' assignments for zsh and bash, "fish"
+// emits `set -g` commands.
+func (h Handoff) Format(dialect string) (string, error) {
+	line, ok := handoffFormats[dialect]
+	if !ok {
+		return "", fmt.Errorf("unknown output format '%s' - use sh or fish", dialect)
+	}
+
+	var b strings.Builder
+	for _, field := range h.fields() {
+		fmt.Fprintf(&b, line, field[0], shellQuote(field[1]))
+	}
+
+	return b.String(), nil
+}
+
+// echoEscape writes raw key bytes with echo-style escapes (\e, \\ and \0NNN)
+// so control characters, including NUL, survive the trip through the shell.
+// zsh decodes them with ${(g::)name}.
+func echoEscape(keys string) string {
+	var b strings.Builder
+	for i := 0; i < len(keys); i++ {
+		c := keys[i]
+		switch {
+		case c == 0x1b:
+			b.WriteString(`\e`)
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\0%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// shellQuote wraps s in single quotes. Quotes and backslashes are placed
+// outside the quoted runs so the result reads the same in POSIX shells and
+// fish, which treats backslashes inside single quotes differently.
+func shellQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			b.WriteString(`'\''`)
+		case '\\':
+			b.WriteString(`'\\'`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", `''`},
+		{"git status", `'git status'`},
+		{"echo 'hi'", `'echo '\''hi'\'''`},
+		{`printf '%s\n' a:b`, `'printf '\''%s'\\'n'\'' a:b'`},
+		{"echo $HOME; ls | wc -l", `'echo $HOME; ls | wc -l'`},
+		{"line one\nline two", "'line one\nline two'"},
+	}
+
+	for _, test := range tests {
+		result := shellQuote(test.input)
+		if result != test.expected {
+			t.Errorf("shellQuote(%q) = %q, want %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	inputs := []string{
+		"git log --format='%h %s'",
+		`sed -e 's/a\/b/c/' file`,
+		"awk -F: '{print $1}' /etc/passwd",
+		`echo "quoted \"string\""`,
+	}
+
+	for _, input := range inputs {
+		words := splitShellWords(shellQuote(input))
+		if len(words) != 1 || words[0] != input {
+			t.Errorf("splitShellWords(shellQuote(%q)) = %q", input, words)
+		}
+	}
+}
+
+func TestHandoffFormat(t *testing.T) {
+	handoff := NewHandoff(Shortcut{
+		Display: "gl",
+		Type:    "command",
+		Target:  "git log --format='%h:%s'",
+		Keymap:  "",
+	}, "tab")
+
+	sh, err := handoff.Format("sh")
+	if err != nil {
+		t.Fatalf("Format(sh) returned error: %v", err)
+	}
+	expected := `SHORTCUTTER_PROTOCOL='1'
+SHORTCUTTER_KEY='tab'
+SHORTCUTTER_TYPE='command'
+SHORTCUTTER_TARGET='git log --format='\''%h:%s'\'''
+SHORTCUTTER_KEYMAP=''
+SHORTCUTTER_KEYS=''
+`
+	if sh != expected {
+		t.Errorf("Format(sh) = %q, want %q", sh, expected)
+	}
+
+	fish, err := handoff.Format("fish")
+	if err != nil {
+		t.Fatalf("Format(fish) returned error: %v", err)
+	}
+	if !strings.HasPrefix(fish, "set -g SHORTCUTTER_PROTOCOL '1'\n") {
+		t.Errorf("Format(fish) = %q, want set -g commands", fish)
+	}
+
+	if _, err := handoff.Format("tcsh"); err == nil {
+		t.Error("Format(tcsh) should return an error")
+	}
+}
+
+func TestIsHandoffFormat(t *testing.T) {
+	if !IsHandoffFormat("sh") || !IsHandoffFormat("fish") {
+		t.Error("sh and fish should be valid handoff formats")
+	}
+	if IsHandoffFormat("json") {
+		t.Error("json should not be a valid handoff format")
+	}
+}
+
+func TestEchoEscape(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"", ""},
+		{"\x01", `\0001`},
+		{"\x18\x05", `\0030\0005`},
+		{"\x1bf", `\ef`},
+		{"\x00", `\0000`},
+		{"\x1b\x7f", `\e\0177`},
+		{`\`, `\\`},
+		{"gg", "gg"},
+	}
+
+	for _, test := range tests {
+		result := echoEscape(test.keys)
+		if result != test.expected {
+			t.Errorf("echoEscape(%q) = %q, want %q", test.keys, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// termKind is how a search term is matched against a shortcut.
+type termKind int
+
+const (
+	termFuzzy  termKind = iota // term: characters in order
+	termExact                  // 'term: a substring
+	termPrefix                 // ^term: the start of a field
+	termSuffix                 // term$: the end of a field
+	termEqual                  // ^term$: a whole field
+	termField                  // field:value: a field qualifier, see queryFields
+)
+
+// queryTerm is one word of a search query.
+type queryTerm struct {
+	text   string
+	kind   termKind
+	negate bool   // !term: the shortcut must not match
+	field  string // Qualifier name of a termField term
+}
+
+// queryFields are the qualifiers a query can narrow the list with, as in
+// "type:command" or "target:git". Each reports whether a shortcut's field
+// matches the lowercased value.
+var queryFields = map[string]func(shortcut Shortcut, value string) bool{
+	"type": func(shortcut Shortcut, value string) bool {
+		return strings.HasPrefix(strings.ToLower(shortcut.Type), value)
+	},
+	"source": func(shortcut Shortcut, value string) bool {
+		return strings.HasPrefix(strings.ToLower(shortcut.Source), value)
+	},
+	"keymap": func(shortcut Shortcut, value string) bool {
+		return strings.HasPrefix(strings.ToLower(shortcut.Keymap), value)
+	},
+	"category": func(shortcut Shortcut, value string) bool {
+		return strings.HasPrefix(strings.ToLower(categoryOf(shortcut)), value)
+	},
+	"custom": func(shortcut Shortcut, value string) bool {
+		switch value {
+		case "yes", "y", "true", "1":
+			return shortcut.IsCustom
+		case "no", "n", "false", "0":
+			return !shortcut.IsCustom
+		}
+		return false
+	},
+	"key": func(shortcut Shortcut, value string) bool {
+		for _, notation := range append([]string{shortcut.Display}, keyNotations(shortcut)...) {
+			if strings.Contains(strings.ToLower(notation), value) {
+				return true
+			}
+		}
+		return false
+	},
+	"target": func(shortcut Shortcut, value string) bool {
+		return strings.Contains(strings.ToLower(shortcut.Target), value)
+	},
+}
+
+// searchQuery is a parsed fzf-style extended query: every group must match,
+// and a group matches when any of its terms does.
+type searchQuery [][]queryTerm
+
+// parseQuery splits a query into space-separated terms. A lone "|" joins
+// the terms either side of it into one group, as in "vi | emacs".
+func parseQuery(query string) searchQuery {
+	var groups searchQuery
+	joining := false
+	for _, word := range strings.Fields(query) {
+		if word == "|" {
+			joining = len(groups) > 0
+			continue
+		}
+		term, ok := parseTerm(word)
+		if !ok {
+			continue
+		}
+		if joining {
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+		} else {
+			groups = append(groups, []queryTerm{term})
+		}
+		joining = false
+	}
+	return groups
+}
+
+// parseTerm reads the !, ', ^ and $ markers around a query word. Negated
+// terms without anchors match exactly, as they do in fzf. A word with a
+// second ^, such as "^X^E", is zsh caret notation rather than an anchor.
+func parseTerm(word string) (queryTerm, bool) {
+	term := queryTerm{kind: termFuzzy}
+	if strings.HasPrefix(word, "!") {
+		term.negate = true
+		term.kind = termExact
+		word = word[1:]
+	}
+
+	caretNotation := strings.Count(word, "^") > 1
+	if name, value, ok := strings.Cut(word, ":"); ok && queryFields[strings.ToLower(name)] != nil {
+		term.kind = termField
+		term.field = strings.ToLower(name)
+		word = value
+	}
+
+	switch {
+	case term.kind == termField:
+		// The value is matched by the qualifier as typed
+	case caretNotation:
+		// Keep the word as typed
+	case strings.HasPrefix(word, "'"):
+		term.kind = termExact
+		word = word[1:]
+	case strings.HasPrefix(word, "^") && len(word) > 1 && strings.HasSuffix(word, "$"):
+		term.kind = termEqual
+		word = word[1 : len(word)-1]
+	case strings.HasPrefix(word, "^"):
+		term.kind = termPrefix
+		word = word[1:]
+	case strings.HasSuffix(word, "$"):
+		term.kind = termSuffix
+		word = word[:len(word)-1]
+	}
+
+	term.text = strings.ToLower(word)
+	return term, term.text != ""
+}
+
+// fuzzy reports whether the query ranks its results by fuzzy score.
+func (q searchQuery) fuzzy() bool {
+	for _, group := range q {
+		for _, term := range group {
+			if term.kind == termFuzzy {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filters lists the field qualifiers of the query as typed, with the
+// alternatives of a group joined by "|".
+func (q searchQuery) filters() []string {
+	var filters []string
+	for _, group := range q {
+		var alternatives []string
+		for _, term := range group {
+			if term.kind != termField {
+				continue
+			}
+			filter := term.field + ":" + term.text
+			if term.negate {
+				filter = "!" + filter
+			}
+			alternatives = append(alternatives, filter)
+		}
+		if len(alternatives) > 0 {
+			filters = append(filters, strings.Join(alternatives, "|"))
+		}
+	}
+	return filters
+}
+
+// match reports whether a shortcut satisfies the query, given its search
+// fields, and its fuzzy score: higher is a better match.
+func (q searchQuery) match(shortcut Shortcut, fields []string) (int, bool) {
+	line := strings.Join(fields, " ")
+	score := 0
+	for _, group := range q {
+		best, matched := 0, false
+		for _, term := range group {
+			termScore, ok := term.match(shortcut, line, fields)
+			if ok == term.negate {
+				continue
+			}
+			if !matched || termScore > best {
+				best = termScore
+			}
+			matched = true
+		}
+		if !matched {
+			return 0, false
+		}
+		score += best
+	}
+	return score, true
+}
+
+// match tests a term against the joined search line; anchored terms are
+// tested against each field, so ^ and $ apply to the key and description.
+func (t queryTerm) match(shortcut Shortcut, line string, fields []string) (int, bool) {
+	switch t.kind {
+	case termField:
+		return 0, queryFields[t.field](shortcut, t.text)
+	case termFuzzy:
+		matches := fuzzy.Find(t.text, []string{line})
+		if len(matches) == 0 {
+			return 0, false
+		}
+		return matches[0].Score, true
+	case termExact:
+		return 0, strings.Contains(strings.ToLower(line), t.text)
+	}
+
+	for _, field := range fields {
+		if t.matchField(strings.ToLower(field)) {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func (t queryTerm) matchField(field string) bool {
+	switch t.kind {
+	case termPrefix:
+		return strings.HasPrefix(field, t.text)
+	case termSuffix:
+		return strings.HasSuffix(field, t.text)
+	case termEqual:
+		return field == t.text
+	}
+	return false
+}
+
+// highlights returns the rune positions in text matched by any positive
+// term of the query.
+func (q searchQuery) highlights(text string) map[int]bool {
+	positions := make(map[int]bool)
+	lower := strings.ToLower(text)
+	// Byte offset of every rune, to turn byte matches into rune positions
+	runeAt := make(map[int]int, len(lower))
+	i := 0
+	for offset := range lower {
+		runeAt[offset] = i
+		i++
+	}
+	mark := func(start, end int) {
+		for offset := start; offset < end; offset++ {
+			if position, ok := runeAt[offset]; ok {
+				positions[position] = true
+			}
+		}
+	}
+
+	for _, group := range q {
+		for _, term := range group {
+			if term.negate || term.kind == termField {
+				continue
+			}
+			switch term.kind {
+			case termFuzzy:
+				for _, match := range fuzzy.Find(term.text, []string{lower}) {
+					for _, offset := range match.MatchedIndexes {
+						mark(offset, offset+1)
+					}
+				}
+			case termExact:
+				for start := 0; ; {
+					found := strings.Index(lower[start:], term.text)
+					if found < 0 {
+						break
+					}
+					mark(start+found, start+found+len(term.text))
+					start += found + len(term.text)
+				}
+			default:
+				// Anchored terms apply to the whole text of the column,
+				// less the padding that aligns it
+				trimmed := strings.TrimRight(lower, " ")
+				if term.matchField(trimmed) {
+					start := 0
+					if term.kind == termSuffix {
+						start = len(trimmed) - len(term.text)
+					}
+					mark(start, start+len(term.text))
+				}
+			}
+		}
+	}
+	return positions
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected searchQuery
+	}{
+		{"", nil},
+		{"kill word", searchQuery{
+			{{text: "kill", kind: termFuzzy}},
+			{{text: "word", kind: termFuzzy}},
+		}},
+		{"!Line 'yank ^ctrl end$ ^tab$", searchQuery{
+			{{text: "line", kind: termExact, negate: true}},
+			{{text: "yank", kind: termExact}},
+			{{text: "ctrl", kind: termPrefix}},
+			{{text: "end", kind: termSuffix}},
+			{{text: "tab", kind: termEqual}},
+		}},
+		{"!^alt", searchQuery{
+			{{text: "alt", kind: termPrefix, negate: true}},
+		}},
+		{"vi | emacs kill", searchQuery{
+			{{text: "vi", kind: termFuzzy}, {text: "emacs", kind: termFuzzy}},
+			{{text: "kill", kind: termFuzzy}},
+		}},
+		{"^X^E", searchQuery{
+			{{text: "^x^e", kind: termFuzzy}},
+		}},
+		{"| ! ' kill |", searchQuery{
+			{{text: "kill", kind: termFuzzy}},
+		}},
+		{"Type:Command !custom:yes git", searchQuery{
+			{{text: "command", kind: termField, field: "type"}},
+			{{text: "yes", kind: termField, field: "custom", negate: true}},
+			{{text: "git", kind: termFuzzy}},
+		}},
+		{"key:ctrl+x | key:^x^e type: http://", searchQuery{
+			{{text: "ctrl+x", kind: termField, field: "key"}, {text: "^x^e", kind: termField, field: "key"}},
+			{{text: "http://", kind: termFuzzy}},
+		}},
+	}
+
+	for _, test := range tests {
+		if got := parseQuery(test.query); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("parseQuery(%q) = %+v, want %+v", test.query, got, test.expected)
+		}
+	}
+}
+
+func TestSearchQueryMatch(t *testing.T) {
+	shortcut := Shortcut{Display: "Ctrl+W", Description: "Kill word backward"}
+	fields := []string{"Ctrl+W", "Kill word backward"}
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"word kill", true},
+		{"kill !backward", false},
+		{"kill !forward", true},
+		{"'ill w", true},
+		{"'wordkill", false},
+		{"^ctrl", true},
+		{"^kill", true},
+		{"^word", false},
+		{"backward$", true},
+		{"^ctrl+w$", true},
+		{"^ctrl$", false},
+		{"yank | kill", true},
+		{"yank | paste", false},
+		{"!^alt word", true},
+	}
+
+	for _, test := range tests {
+		if _, got := parseQuery(test.query).match(shortcut, fields); got != test.expected {
+			t.Errorf("%q match %v = %v, want %v", test.query, fields, got, test.expected)
+		}
+	}
+}
+
+func TestSearchQueryHighlights(t *testing.T) {
+	tests := []struct {
+		query    string
+		text     string
+		expected []int
+	}{
+		{"kill word", "Kill word", []int{0, 1, 2, 3, 5, 6, 7, 8}},
+		{"'ll", "Kill all", []int{2, 3, 6, 7}},
+		{"^ctrl", "Ctrl+W      ", []int{0, 1, 2, 3}},
+		{"+w$", "Ctrl+W      ", []int{4, 5}},
+		{"!kill word", "Kill word", []int{5, 6, 7, 8}},
+		{"yank | ^kill", "Kill word", []int{0, 1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		positions := parseQuery(test.query).highlights(test.text)
+		expected := make(map[int]bool)
+		for _, position := range test.expected {
+			expected[position] = true
+		}
+		if !reflect.DeepEqual(positions, expected) {
+			t.Errorf("%q highlights in %q = %v, want %v", test.query, test.text, positions, expected)
+		}
+	}
+}
+
+func TestSearchQueryFields(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+X Ctrl+E", Keys: KeySequence{{Key: "x", Modifiers: ModCtrl}, {Key: "e", Modifiers: ModCtrl}}, Description: "Edit command line", Type: "widget", Target: "edit-command-line", Keymap: "emacs", Category: "editing", Source: "binding"},
+		{Display: "gs", Description: "git status", Type: "command", Target: "git status", Category: "aliases", Source: "alias"},
+		{Display: "Ctrl+G", Keys: KeySequence{{Key: "g", Modifiers: ModCtrl}}, Description: "Git log", Type: "command", Target: "git log --graph", IsCustom: true, Category: "custom", Source: "config"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"type:command", []string{"gs", "Ctrl+G"}},
+		{"type:w", []string{"Ctrl+X Ctrl+E"}},
+		{"custom:yes", []string{"Ctrl+G"}},
+		{"custom:no", []string{"Ctrl+X Ctrl+E", "gs"}},
+		{"custom:maybe", nil},
+		{"source:alias", []string{"gs"}},
+		{"key:ctrl+x", []string{"Ctrl+X Ctrl+E"}},
+		{"key:^x^e", []string{"Ctrl+X Ctrl+E"}},
+		{"key:c-g", []string{"Ctrl+G"}},
+		{"target:git", []string{"gs", "Ctrl+G"}},
+		{"target:git !custom:yes", []string{"gs"}},
+		{"category:edit | keymap:emacs", []string{"Ctrl+X Ctrl+E"}},
+		{"type:command log", []string{"Ctrl+G"}},
+	}
+
+	for _, test := range tests {
+		query := parseQuery(test.query)
+		var got []string
+		for _, shortcut := range shortcuts {
+			fields := append([]string{shortcut.Display, shortcut.Description}, keyNotations(shortcut)...)
+			if _, ok := query.match(shortcut, fields); ok {
+				got = append(got, shortcut.Display)
+			}
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q matched %v, want %v", test.query, got, test.expected)
+		}
+	}
+}
+
+func TestSearchQueryFilters(t *testing.T) {
+	filters := parseQuery("kill type:widget | type:command !custom:yes").filters()
+	expected := []string{"type:widget|type:command", "!custom:yes"}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("filters() = %v, want %v", filters, expected)
+	}
+}
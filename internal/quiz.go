@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// quizModel is the learn mode: it shows a shortcut's description and waits
+// for its keys to be pressed.
+type quizModel struct {
+	queue    []Shortcut // Cards left, the current one first
+	retries  map[string]bool
+	progress *LearnProgress
+	styles   ThemeStyles
+	clock    func() time.Time
+	asked    time.Time // When the current card was shown
+	pressed  string    // Bytes pressed so far for the current card
+	answered bool      // The current card is answered and its result shown
+	correct  bool
+	summary  LearnSummary
+	quitting bool
+	width    int
+}
+
+func newQuizModel(cards []Shortcut, progress *LearnProgress, styles ThemeStyles) quizModel {
+	m := quizModel{
+		queue:    cards,
+		retries:  make(map[string]bool),
+		progress: progress,
+		styles:   styles,
+		clock:    time.Now,
+	}
+	m.asked = m.clock()
+	return m
+}
+
+func (m quizModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m quizModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.quitting = true
+			m.summary.Left = len(m.queue)
+			return m, tea.Quit
+		}
+
+		if m.answered {
+			m.queue = m.queue[1:]
+			m.pressed = ""
+			m.answered = false
+			m.asked = m.clock()
+			if len(m.queue) == 0 {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		keys, ok := teaKeyBytes(msg)
+		if !ok {
+			return m, nil
+		}
+		m.pressed += keys
+		expected := m.queue[0].Keys.Bytes()
+		if m.pressed == expected {
+			return m.grade(true), nil
+		}
+		if !strings.HasPrefix(expected, m.pressed) {
+			return m.grade(false), nil
+		}
+	}
+
+	return m, nil
+}
+
+// grade records the answer to the current card. The first answer in a
+// session schedules the card; cards answered badly are asked again at the
+// end of the session until they are answered well, as SM-2 prescribes.
+func (m quizModel) grade(correct bool) quizModel {
+	now := m.clock()
+	shortcut := m.queue[0]
+	key := usageKey(shortcut)
+	quality := answerQuality(correct, now.Sub(m.asked))
+
+	if !m.retries[key] {
+		m.progress.Cards[key] = m.progress.Cards[key].review(quality, now)
+		m.summary.Reviewed++
+		if correct {
+			m.summary.Correct++
+		}
+	}
+	if quality < 4 {
+		m.retries[key] = true
+		m.queue = append(m.queue, shortcut)
+	}
+
+	m.answered = true
+	m.correct = correct
+	return m
+}
+
+func (m quizModel) View() string {
+	if m.quitting || len(m.queue) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	shortcut := m.queue[0]
+
+	status := fmt.Sprintf("  %d left ", len(m.queue))
+	b.WriteString(m.styles.Status.Render(status))
+	if separatorLength := m.width - len(status) - 2; separatorLength > 0 {
+		b.WriteString(m.styles.Separator.Render(strings.Repeat("─", separatorLength)))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("  ")
+	b.WriteString(m.styles.Title.Render(shortcut.Description))
+	b.WriteString("\n\n  ")
+
+	answer := shortcut.Keys.Display()
+	switch {
+	case !m.answered:
+		prompt := "Press the shortcut"
+		if m.pressed != "" {
+			prompt += ": " + keySequenceFromBytes(m.pressed).Display() + " …"
+		}
+		b.WriteString(m.styles.Query.Render(prompt))
+	case m.correct:
+		b.WriteString(m.styles.Command.Render("✓ " + answer))
+	default:
+		b.WriteString(m.styles.CustomIndicator.Render("✗ " + keySequenceFromBytes(m.pressed).Display()))
+		b.WriteString(m.styles.Description.Render("  the shortcut is "))
+		b.WriteString(m.styles.Command.Render(answer))
+	}
+	b.WriteString("\n\n")
+
+	help := "Ctrl+C: quit"
+	if m.answered {
+		card := m.progress.Cards[usageKey(shortcut)]
+		help = fmt.Sprintf("Next review in %s • any key: continue • %s", pluralDays(card.Interval), help)
+	}
+	b.WriteString(m.styles.Help.Render(help))
+
+	return m.styles.AppBackground.Render(b.String())
+}
+
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// teaKeyNames maps Bubble Tea key names to the names ParseKeySequence knows.
+var teaKeyNames = map[string]string{
+	"pgup":   "prior",
+	"pgdown": "next",
+	" ":      "Space",
+}
+
+// teaKeyBytes returns the bytes the terminal sent for a key press, so they
+// can be compared with KeySequence.Bytes. Pastes and non-ASCII runes, which
+// no binding uses, are rejected.
+func teaKeyBytes(msg tea.KeyMsg) (string, bool) {
+	var keys string
+	switch {
+	case msg.Paste:
+		return "", false
+	case msg.Type == tea.KeyRunes:
+		if len(msg.Runes) != 1 || msg.Runes[0] >= 0x7f {
+			return "", false
+		}
+		keys = string(msg.Runes)
+	case msg.Type >= 0:
+		// Control characters are their own key type
+		keys = string([]byte{byte(msg.Type)})
+	default:
+		name := strings.TrimPrefix(msg.String(), "alt+")
+		if mapped, ok := teaKeyNames[name]; ok {
+			name = mapped
+		}
+		chords, err := parseChords(name)
+		if err != nil {
+			return "", false
+		}
+		keys = KeySequence(chords).Bytes()
+	}
+
+	if msg.Alt {
+		keys = "\x1b" + keys
+	}
+	return keys, true
+}
+
+// ShowLearn runs learn mode over cards, recording each answer in progress,
+// and returns how the session went. The caller saves progress.
+func ShowLearn(cards []Shortcut, progress *LearnProgress, styles ThemeStyles) (LearnSummary, error) {
+	// Force true color support
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	m := newQuizModel(cards, progress, styles)
+
+	options := []tea.ProgramOption{}
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		defer tty.Close()
+		options = append(options, tea.WithInput(tty), tea.WithOutput(tty))
+	}
+
+	finalModel, err := tea.NewProgram(m, options...).Run()
+	if err != nil {
+		return LearnSummary{}, err
+	}
+	if finalModel, ok := finalModel.(quizModel); ok {
+		return finalModel.summary, nil
+	}
+	return LearnSummary{}, nil
+}
+
+// LearnSession picks the cards for a learn session from shortcuts bound in
+// keymap, or in every keymap when it is empty: the due ones, then up to
+// newCards new ones.
+func LearnSession(shortcuts []Shortcut, progress *LearnProgress, keymap string, newCards int) []Shortcut {
+	var candidates []Shortcut
+	for _, shortcut := range shortcuts {
+		if keymap == "" || shortcut.Keymap == "" || shortcut.Keymap == keymap {
+			candidates = append(candidates, shortcut)
+		}
+	}
+	return progress.session(candidates, newCards, time.Now())
+}
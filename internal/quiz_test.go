@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTeaKeyBytes(t *testing.T) {
+	tests := []struct {
+		msg      tea.KeyMsg
+		expected string
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlA}, "\x01"},
+		{tea.KeyMsg{Type: tea.KeyTab}, "\t"},
+		{tea.KeyMsg{Type: tea.KeyEsc}, "\x1b"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f"), Alt: true}, "\x1bf"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")}, "G"},
+		{tea.KeyMsg{Type: tea.KeySpace}, " "},
+		{tea.KeyMsg{Type: tea.KeyCtrlUnderscore}, "\x1f"},
+	}
+
+	for _, test := range tests {
+		got, ok := teaKeyBytes(test.msg)
+		if !ok || got != test.expected {
+			t.Errorf("teaKeyBytes(%s) = %q, %v, want %q", test.msg, got, ok, test.expected)
+		}
+	}
+
+	if _, ok := teaKeyBytes(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ab"), Paste: true}); ok {
+		t.Error("teaKeyBytes should reject pastes")
+	}
+
+	up, _ := ParseKeySequence("Up")
+	if got, ok := teaKeyBytes(tea.KeyMsg{Type: tea.KeyUp}); !ok || got != up.Bytes() {
+		t.Errorf("teaKeyBytes(up) = %q, want %q", got, up.Bytes())
+	}
+}
+
+func TestQuizModel(t *testing.T) {
+	editKeys, _ := ParseKeySequence("Ctrl+X Ctrl+E")
+	lineKeys, _ := ParseKeySequence("Ctrl+A")
+	cards := []Shortcut{
+		{Display: "Ctrl+X Ctrl+E", Keys: editKeys, Description: "Edit command line"},
+		{Display: "Ctrl+A", Keys: lineKeys, Description: "Beginning of line"},
+	}
+	progress := &LearnProgress{Cards: make(map[string]Card)}
+
+	m := newQuizModel(cards, progress, CreateThemeStyles(GetDefaultTheme()))
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	m.clock = func() time.Time { return now }
+	m.asked = now
+
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(quizModel)
+	}
+
+	if !strings.Contains(m.View(), "Edit command line") {
+		t.Errorf("View should ask for the first card:\n%s", m.View())
+	}
+
+	// A two-chord sequence waits for the second chord
+	press(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if m.answered {
+		t.Fatal("Ctrl+X alone should not answer Ctrl+X Ctrl+E")
+	}
+	press(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if !m.answered || !m.correct {
+		t.Fatal("Ctrl+X Ctrl+E should answer the first card")
+	}
+	if card := progress.Cards[usageKey(cards[0])]; card.Repetitions != 1 {
+		t.Errorf("Correct answer should be scheduled, got %+v", card)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if !m.answered || m.correct {
+		t.Fatal("Ctrl+E should answer Ctrl+A wrongly")
+	}
+	if !strings.Contains(m.View(), "Ctrl+A") {
+		t.Errorf("View should reveal the right keys:\n%s", m.View())
+	}
+
+	// The wrong card comes back once more; a retry doesn't reschedule it
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.queue) != 1 || m.queue[0].Display != "Ctrl+A" {
+		t.Fatalf("Wrong card should be asked again, queue %+v", m.queue)
+	}
+	press(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if card := progress.Cards[usageKey(cards[1])]; card.Reviews != 1 || card.Repetitions != 0 {
+		t.Errorf("Retry should not reschedule the card, got %+v", card)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(quizModel)
+	if cmd == nil || !m.quitting {
+		t.Error("Session should end after the last card")
+	}
+	if m.summary.Reviewed != 2 || m.summary.Correct != 1 {
+		t.Errorf("Summary: got %+v, want 2 reviewed, 1 correct", m.summary)
+	}
+}
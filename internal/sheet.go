@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sheetTypes are the shortcut types in the order the sheet shows their
+// groups, with their headings. Other types follow alphabetically.
+var sheetTypes = []struct {
+	name  string
+	title string
+}{
+	{"widget", "Widgets"},
+	{"command", "Commands"},
+	{"sequence", "Sequences"},
+	{"insert", "Insertions"},
+}
+
+const (
+	sheetGap                 = 3  // Spaces between columns
+	sheetMaxKeyWidth         = 16 // Longer keys are cut short
+	sheetMaxDescriptionWidth = 40
+	sheetDefaultWidth        = 80 // Width when the terminal's is unknown
+)
+
+// sheetGroup is the shortcuts of one type, in the order given.
+type sheetGroup struct {
+	title     string
+	shortcuts []Shortcut
+}
+
+// sheetGroups splits shortcuts by type, known types first in sheetTypes.
+// When they span several keymaps each type is split further by keymap, so
+// the same key in emacs and vicmd is told apart by the keymap in the title.
+func sheetGroups(shortcuts []Shortcut) []sheetGroup {
+	keymaps := availableKeymaps(shortcuts)
+
+	byType := make(map[string][]Shortcut)
+	for _, shortcut := range shortcuts {
+		shortcutType := shortcut.Type
+		if shortcutType == "" {
+			shortcutType = "other"
+		}
+		byType[shortcutType] = append(byType[shortcutType], shortcut)
+	}
+
+	var groups []sheetGroup
+	for _, known := range sheetTypes {
+		if list, ok := byType[known.name]; ok {
+			groups = append(groups, keymapGroups(known.title, list, keymaps)...)
+			delete(byType, known.name)
+		}
+	}
+	var others []string
+	for shortcutType := range byType {
+		others = append(others, shortcutType)
+	}
+	sort.Strings(others)
+	for _, shortcutType := range others {
+		groups = append(groups, keymapGroups(categoryTitle(shortcutType), byType[shortcutType], keymaps)...)
+	}
+	return groups
+}
+
+// keymapGroups splits the shortcuts of one type by keymap, in the order of
+// keymaps, after those bound in every keymap. With one keymap or none they
+// stay a single group.
+func keymapGroups(title string, shortcuts []Shortcut, keymaps []string) []sheetGroup {
+	if len(keymaps) <= 1 {
+		return []sheetGroup{{title: title, shortcuts: shortcuts}}
+	}
+
+	var groups []sheetGroup
+	for _, keymap := range append([]string{""}, keymaps...) {
+		group := sheetGroup{title: title}
+		if keymap != "" {
+			group.title = fmt.Sprintf("%s [%s]", title, keymap)
+		}
+		for _, shortcut := range shortcuts {
+			if shortcut.Keymap == keymap {
+				group.shortcuts = append(group.shortcuts, shortcut)
+			}
+		}
+		if len(group.shortcuts) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// sheetLines lays out shortcuts as a cheat sheet in as many columns as fit
+// in width. Groups run down one column and on into the next, each under a
+// heading with its size; keys and descriptions too long for the column are
+// cut short.
+func sheetLines(shortcuts []Shortcut, styles ThemeStyles, keyStyle string, width int) []string {
+	groups := sheetGroups(shortcuts)
+	if len(groups) == 0 {
+		return nil
+	}
+	if width <= 0 {
+		width = sheetDefaultWidth
+	}
+
+	keyWidth, descriptionWidth := 1, 1
+	for _, shortcut := range shortcuts {
+		keyWidth = max(keyWidth, lipgloss.Width(shortcut.KeyLabel(keyStyle)))
+		descriptionWidth = max(descriptionWidth, lipgloss.Width(shortcut.Description))
+	}
+	keyWidth = min(keyWidth, sheetMaxKeyWidth)
+	descriptionWidth = min(descriptionWidth, sheetMaxDescriptionWidth)
+
+	// A cell is the key, two spaces, the description and the custom marker
+	cellWidth := keyWidth + 2 + descriptionWidth + 1
+	columns := max(1, (width+sheetGap)/(cellWidth+sheetGap))
+	if columns == 1 && cellWidth > width {
+		descriptionWidth = max(1, width-keyWidth-3)
+		cellWidth = keyWidth + 2 + descriptionWidth + 1
+	}
+
+	var cells []string
+	for i, group := range groups {
+		if i > 0 {
+			cells = append(cells, strings.Repeat(" ", cellWidth))
+		}
+		heading := fmt.Sprintf("%s (%d)", group.title, len(group.shortcuts))
+		cells = append(cells, styles.Title.Render(fitText(heading, cellWidth)))
+
+		for _, shortcut := range group.shortcuts {
+			indicator := " "
+			if shortcut.IsCustom {
+				indicator = styles.CustomIndicator.Render("*")
+			}
+			cells = append(cells, styles.Command.Render(fitText(shortcut.KeyLabel(keyStyle), keyWidth))+
+				"  "+styles.Description.Render(fitText(shortcut.Description, descriptionWidth))+indicator)
+		}
+	}
+
+	rows := (len(cells) + columns - 1) / columns
+	lines := make([]string, rows)
+	for row := range lines {
+		var line strings.Builder
+		for column := 0; column < columns; column++ {
+			index := column*rows + row
+			if index >= len(cells) {
+				break
+			}
+			if column > 0 {
+				line.WriteString(strings.Repeat(" ", sheetGap))
+			}
+			line.WriteString(cells[index])
+		}
+		lines[row] = line.String()
+	}
+	return lines
+}
+
+// fitText pads text with spaces to width, or cuts it short with an ellipsis.
+func fitText(text string, width int) string {
+	if runes := []rune(text); len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", max(0, width-lipgloss.Width(text)))
+}
+
+// RenderSheet lays out shortcuts as a cheat sheet grouped by type, in as
+// many columns as fit in width, see sheetLines.
+func RenderSheet(shortcuts []Shortcut, styles ThemeStyles, keyStyle string, width int) string {
+	return strings.Join(sheetLines(shortcuts, styles, keyStyle, width), "\n")
+}
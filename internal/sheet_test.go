@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSheetGroups(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "gs", Type: "command", Target: "git status"},
+		{Display: "Ctrl+Z", Type: "sequence", Target: "C-z"},
+		{Display: "Ctrl+A", Type: "widget", Target: "beginning-of-line"},
+		{Display: "x", Type: "macro"},
+		{Display: "Ctrl+E", Type: "widget", Target: "end-of-line"},
+	}
+
+	groups := sheetGroups(shortcuts)
+	var titles []string
+	for _, group := range groups {
+		titles = append(titles, group.title)
+	}
+	if got := strings.Join(titles, ","); got != "Widgets,Commands,Sequences,Macro" {
+		t.Errorf("sheetGroups() titles = %s, want Widgets,Commands,Sequences,Macro", got)
+	}
+	if len(groups[0].shortcuts) != 2 || groups[0].shortcuts[1].Display != "Ctrl+E" {
+		t.Errorf("Widgets should keep their order: %+v", groups[0].shortcuts)
+	}
+}
+
+func TestSheetGroupsKeymaps(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Tab", Description: "Complete", Type: "widget", Keymap: "emacs"},
+		{Display: "Tab", Description: "Complete", Type: "widget", Keymap: "viins"},
+		{Display: "x", Description: "Delete character", Type: "widget", Keymap: "vicmd"},
+		{Display: "Enter", Description: "Accept line", Type: "widget"},
+		{Display: "gs", Type: "command", Target: "git status"},
+	}
+
+	var titles []string
+	for _, group := range sheetGroups(shortcuts) {
+		titles = append(titles, group.title)
+	}
+	want := "Widgets,Widgets [emacs],Widgets [viins],Widgets [vicmd],Commands"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("sheetGroups() titles = %s, want %s", got, want)
+	}
+
+	if keymap := ActiveKeymap(shortcuts, ""); keymap != "emacs" {
+		t.Errorf("ActiveKeymap() = %q, want emacs", keymap)
+	}
+	if keymap := ActiveKeymap(shortcuts, "vicmd"); keymap != "vicmd" {
+		t.Errorf("ActiveKeymap(vicmd) = %q, want vicmd", keymap)
+	}
+	if keymap := ActiveKeymap(shortcuts[2:], "visual"); keymap != "" {
+		t.Errorf("ActiveKeymap(visual) without emacs = %q, want every keymap", keymap)
+	}
+}
+
+func TestSheetLines(t *testing.T) {
+	var shortcuts []Shortcut
+	for _, key := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+		shortcuts = append(shortcuts, Shortcut{Display: "Ctrl+" + key, Description: "Widget " + key, Type: "widget"})
+	}
+	shortcuts = append(shortcuts, Shortcut{Display: "gs", Description: "Git status", Type: "command", IsCustom: true})
+	styles := CreateThemeStyles(GetDefaultTheme())
+
+	// Each cell is 6 + 2 + 10 + 1 = 19 wide, so 63 columns fit 3 of them
+	// with their gaps
+	lines := sheetLines(shortcuts, styles, "", 63)
+	if len(lines) != 4 {
+		t.Fatalf("sheetLines() at width 63: got %d lines, want 4:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	for _, line := range lines {
+		if width := lipgloss.Width(line); width > 63 {
+			t.Errorf("Line is %d wide, more than 63: %q", width, line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "Widgets (8)") {
+		t.Errorf("The sheet should open with the widgets heading: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "Commands (1)") || !strings.Contains(lines[3], "gs") || !strings.Contains(lines[3], "*") {
+		t.Errorf("The commands group should follow in the last column, gs marked custom:\n%s", strings.Join(lines, "\n"))
+	}
+
+	if lines := sheetLines(shortcuts, styles, "", 62); len(lines) != 6 {
+		t.Errorf("sheetLines() at width 62: got %d lines, want two columns of 6", len(lines))
+	}
+
+	lines = sheetLines(shortcuts, styles, "", 12)
+	if width := lipgloss.Width(lines[1]); width != 12 {
+		t.Errorf("A narrow terminal should cut descriptions short to fit: line is %d wide", width)
+	}
+	if !strings.Contains(lines[1], "…") {
+		t.Errorf("Cut descriptions should end in an ellipsis: %q", lines[1])
+	}
+
+	if lines := sheetLines(nil, styles, "", 80); len(lines) != 0 {
+		t.Errorf("sheetLines() with no shortcuts: got %d lines, want none", len(lines))
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got := fitText("Ctrl+A", 8); got != "Ctrl+A  " {
+		t.Errorf("fitText() padded = %q, want %q", got, "Ctrl+A  ")
+	}
+	if got := fitText("Beginning of line", 8); got != "Beginni…" {
+		t.Errorf("fitText() cut = %q, want %q", got, "Beginni…")
+	}
+}
@@ -12,29 +12,63 @@ import (
 )
 
 type Shortcut struct {
-	Display     string // What to show in UI (e.g., "Ctrl+A", "gs")
-	Description string // Human-readable description
-	Type        string // "widget", "command", or "sequence"
-	Target      string // What to execute (widget name, command, or key sequence)
-	IsCustom    bool   // True if added/modified by user config
+	Display     string      // What to show in UI (e.g., "Ctrl+A", "gs")
+	Keys        KeySequence // Keys that trigger the shortcut, nil for aliases and functions
+	Description string      // Human-readable description
+	Type        string      // "widget", "command", "sequence" or "insert"
+	Target      string      // What to execute (widget name, command, or key sequence)
+	Keymap      string      // Keymap the binding belongs to ("emacs", "viins", "vicmd", "visual"), empty if keymap-independent
+	IsCustom    bool        // True if added/modified by user config
+	Category    string      // Section the picker groups the shortcut under, e.g. "movement"
+	Source      string      // Where the shortcut came from: "binding", "alias", "function" or "config"
 }
 
 type Config struct {
 	Shortcuts map[string]interface{} `toml:"shortcuts"`
 	Theme     ThemeConfig            `toml:"theme"`
+	UI        UIConfig               `toml:"ui"`
 }
 
 type ThemeConfig struct {
 	Name string `toml:"name"`
 }
 
+type UIConfig struct {
+	KeyStyle string `toml:"key_style"` // One of KeyStyles, empty for the default
+	Frecency *bool  `toml:"frecency"`  // Rank often and recently picked shortcuts first, on unless false
+	Stats    *bool  `toml:"stats"`     // Log selections for `shortcutter stats`, on unless false
+}
+
+// FrecencyEnabled reports whether the picker ranks shortcuts by usage.
+func (c UIConfig) FrecencyEnabled() bool {
+	return c.Frecency == nil || *c.Frecency
+}
+
+// StatsEnabled reports whether selections are logged for the stats command.
+func (c UIConfig) StatsEnabled() bool {
+	return c.Stats == nil || *c.Stats
+}
+
+// ShellState holds live data captured from the running shell by the
+// integration script. Empty fields fall back to the built-in catalog.
+type ShellState struct {
+	Bindings  string // Output of `bindkey -L` (zsh), `bind -p` and `bind -X` (bash) or `bind` (fish)
+	Aliases   string // Output of `alias -L` (zsh), `alias -p` (bash) or `alias` (fish)
+	Functions string // Function definitions from `functions` or `declare -f`, or one name per line
+}
+
 func LoadShortcuts() ([]Shortcut, error) {
+	return LoadShortcutsFromShell(ShellState{})
+}
+
+// LoadShortcutsFromShell loads shortcuts using live shell state where available.
+func LoadShortcutsFromShell(state ShellState) ([]Shortcut, error) {
 	shell, err := detectShell()
 	if err != nil {
 		return nil, err
 	}
 
-	builtins, err := getBuiltinShortcuts(shell)
+	builtins, err := getShellShortcuts(shell, state)
 	if err != nil {
 		return nil, err
 	}
@@ -50,16 +84,26 @@ func LoadShortcuts() ([]Shortcut, error) {
 }
 
 func LoadShortcutsAndTheme() ([]Shortcut, ThemeStyles, error) {
-	shortcuts, err := LoadShortcuts()
+	return LoadShortcutsAndThemeFromShell(ShellState{})
+}
+
+func LoadShortcutsAndThemeFromShell(state ShellState) ([]Shortcut, ThemeStyles, error) {
+	shortcuts, err := LoadShortcutsFromShell(state)
 	if err != nil {
 		return nil, ThemeStyles{}, err
 	}
 
+	styles := CreateThemeStyles(LoadConfiguredTheme())
+
+	return shortcuts, styles, nil
+}
+
+// LoadConfiguredTheme returns the theme named in the config, falling back
+// to the default theme when it can't be loaded.
+func LoadConfiguredTheme() Theme {
 	config, err := loadConfig()
 	if err != nil {
-		defaultTheme := GetDefaultTheme()
-		styles := CreateThemeStyles(defaultTheme)
-		return shortcuts, styles, nil
+		return GetDefaultTheme()
 	}
 
 	themeName := config.Theme.Name
@@ -71,10 +115,17 @@ func LoadShortcutsAndTheme() ([]Shortcut, ThemeStyles, error) {
 	if err != nil {
 		theme = GetDefaultTheme()
 	}
+	return theme
+}
 
-	styles := CreateThemeStyles(theme)
-
-	return shortcuts, styles, nil
+// LoadUIConfig returns the [ui] settings, falling back to the defaults when
+// the config can't be read.
+func LoadUIConfig() UIConfig {
+	config, err := loadConfig()
+	if err != nil {
+		return UIConfig{}
+	}
+	return config.UI
 }
 
 func detectShell() (string, error) {
@@ -89,24 +140,188 @@ func detectShell() (string, error) {
 	case "zsh":
 		return "zsh", nil
 	case "bash":
-		return "", fmt.Errorf("bash support not implemented yet - please use zsh")
+		return "bash", nil
 	case "fish":
-		return "", fmt.Errorf("fish support not implemented yet - please use zsh")
+		return "fish", nil
 	default:
-		return "", fmt.Errorf("unsupported shell '%s' - only zsh is supported", shellName)
+		return "", fmt.Errorf("unsupported shell '%s' - only zsh, bash and fish are supported", shellName)
 	}
 }
 
 func getBuiltinShortcuts(shell string) ([]Shortcut, error) {
+	var shortcuts []Shortcut
 	switch shell {
 	case "zsh":
-		return getZshBuiltinShortcuts(), nil
+		shortcuts = append(getZshBuiltinShortcuts(), getZshViBuiltinShortcuts()...)
+	case "bash":
+		shortcuts = getBashBuiltinShortcuts()
+	case "fish":
+		shortcuts = getFishBuiltinShortcuts()
 	default:
 		return nil, fmt.Errorf("no built-in shortcuts available for shell: %s", shell)
 	}
+
+	for i := range shortcuts {
+		shortcuts[i].Keys, _ = ParseKeySequence(shortcuts[i].Display)
+	}
+	return shortcuts, nil
+}
+
+func getShellShortcuts(shell string, state ShellState) ([]Shortcut, error) {
+	var shortcuts []Shortcut
+	if state.Bindings != "" {
+		shortcuts = parseBindings(shell, state.Bindings)
+	}
+
+	if len(shortcuts) == 0 {
+		builtins, err := getBuiltinShortcuts(shell)
+		if err != nil {
+			return nil, err
+		}
+		shortcuts = builtins
+	}
+	for i := range shortcuts {
+		shortcuts[i].Source = "binding"
+	}
+
+	if state.Aliases != "" {
+		shortcuts = append(shortcuts, parseAliasOutput(state.Aliases)...)
+	}
+
+	if state.Functions != "" {
+		shortcuts = append(shortcuts, parseFunctionOutput(state.Functions)...)
+	}
+
+	for i := range shortcuts {
+		if shortcuts[i].Category == "" {
+			shortcuts[i].Category = categorize(shortcuts[i])
+		}
+	}
+
+	return shortcuts, nil
+}
+
+func parseBindings(shell string, output string) []Shortcut {
+	switch shell {
+	case "zsh":
+		return parseBindkeyOutput(output)
+	case "bash":
+		return parseBindOutput(output)
+	case "fish":
+		return parseFishBindOutput(output)
+	default:
+		return nil
+	}
 }
 
 func getZshBuiltinShortcuts() []Shortcut {
+	return []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of the line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+E", Description: "End of the line", Type: "widget", Target: "end-of-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+F", Description: "Forward one character", Type: "widget", Target: "forward-char", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+B", Description: "Back one character", Type: "widget", Target: "backward-char", Keymap: "emacs", IsCustom: false},
+		{Display: "Alt+F", Description: "Forward one word", Type: "widget", Target: "forward-word", Keymap: "emacs", IsCustom: false},
+		{Display: "Alt+B", Description: "Back one word", Type: "widget", Target: "backward-word", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+T", Description: "Swap cursor with prev character", Type: "widget", Target: "transpose-chars", Keymap: "emacs", IsCustom: false},
+		{Display: "Alt+T", Description: "Swap cursor with prev word", Type: "widget", Target: "transpose-words", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+U", Description: "Clear to beginning of line", Type: "widget", Target: "backward-kill-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+K", Description: "Kill to end of line", Type: "widget", Target: "kill-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+H", Description: "Kill one character backward", Type: "widget", Target: "backward-delete-char", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+W", Description: "Kill word back (if no Mark)", Type: "widget", Target: "backward-kill-word", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+@", Description: "Set Mark", Type: "widget", Target: "set-mark-command", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+Y", Description: "Paste from Kill Ring", Type: "widget", Target: "yank", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+V", Description: "Quoted insert", Type: "widget", Target: "quoted-insert", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+Q", Description: "Push line to be used again", Type: "widget", Target: "push-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+_", Description: "Undo", Type: "widget", Target: "undo", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+P", Description: "Prev line", Type: "widget", Target: "up-line-or-history", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+N", Description: "Next Line", Type: "widget", Target: "down-line-or-history", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+R", Description: "Search", Type: "widget", Target: "history-incremental-search-backward", Keymap: "emacs", IsCustom: false},
+		{Display: "Alt+P", Description: "Match word on line", Type: "widget", Target: "history-search-backward", Keymap: "emacs", IsCustom: false},
+		{Display: "Alt+.", Description: "Extract last word", Type: "widget", Target: "insert-last-word", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+L", Description: "Clear screen", Type: "widget", Target: "clear-screen", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+S", Description: "Stop screen output", Type: "sequence", Target: "C-s", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+C", Description: "Kill proc", Type: "sequence", Target: "C-c", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+Z", Description: "Suspend proc", Type: "sequence", Target: "C-z", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+O", Description: "Exec cmd but keep line", Type: "widget", Target: "accept-line-and-down-history", Keymap: "emacs", IsCustom: false},
+		{Display: "Tab", Description: "Complete command/filename", Type: "widget", Target: "expand-or-complete", Keymap: "emacs", IsCustom: false},
+		{Display: "Enter", Description: "Execute command", Type: "widget", Target: "accept-line", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+D", Description: "Delete character or EOF", Type: "widget", Target: "delete-char-or-list", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+G", Description: "Abort current operation", Type: "widget", Target: "send-break", Keymap: "emacs", IsCustom: false},
+		{Display: "Ctrl+X Ctrl+E", Description: "Edit command in editor", Type: "widget", Target: "edit-command-line", Keymap: "emacs", IsCustom: false},
+		{Display: "↑", Description: "Previous command in history", Type: "widget", Target: "up-line-or-history", Keymap: "emacs", IsCustom: false},
+		{Display: "↓", Description: "Next command in history", Type: "widget", Target: "down-line-or-history", Keymap: "emacs", IsCustom: false},
+		{Display: "←", Description: "Move cursor left", Type: "widget", Target: "backward-char", Keymap: "emacs", IsCustom: false},
+		{Display: "→", Description: "Move cursor right", Type: "widget", Target: "forward-char", Keymap: "emacs", IsCustom: false},
+		{Display: "Home", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs", IsCustom: false},
+		{Display: "End", Description: "End of line", Type: "widget", Target: "end-of-line", Keymap: "emacs", IsCustom: false},
+	}
+}
+
+func getZshViBuiltinShortcuts() []Shortcut {
+	return []Shortcut{
+		{Display: "Esc", Description: "Switch to command mode", Type: "widget", Target: "vi-cmd-mode", Keymap: "viins", IsCustom: false},
+		{Display: "Ctrl+H", Description: "Kill one character backward", Type: "widget", Target: "vi-backward-delete-char", Keymap: "viins", IsCustom: false},
+		{Display: "Ctrl+W", Description: "Kill word backward", Type: "widget", Target: "vi-backward-kill-word", Keymap: "viins", IsCustom: false},
+		{Display: "Ctrl+U", Description: "Clear to beginning of line", Type: "widget", Target: "vi-kill-line", Keymap: "viins", IsCustom: false},
+		{Display: "Ctrl+V", Description: "Quoted insert", Type: "widget", Target: "vi-quoted-insert", Keymap: "viins", IsCustom: false},
+		{Display: "Ctrl+D", Description: "List completions", Type: "widget", Target: "list-choices", Keymap: "viins", IsCustom: false},
+		{Display: "Tab", Description: "Complete command/filename", Type: "widget", Target: "expand-or-complete", Keymap: "viins", IsCustom: false},
+		{Display: "Enter", Description: "Execute command", Type: "widget", Target: "accept-line", Keymap: "viins", IsCustom: false},
+		{Display: "h", Description: "Back one character", Type: "widget", Target: "vi-backward-char", Keymap: "vicmd", IsCustom: false},
+		{Display: "l", Description: "Forward one character", Type: "widget", Target: "vi-forward-char", Keymap: "vicmd", IsCustom: false},
+		{Display: "k", Description: "Prev line", Type: "widget", Target: "up-line-or-history", Keymap: "vicmd", IsCustom: false},
+		{Display: "j", Description: "Next Line", Type: "widget", Target: "down-line-or-history", Keymap: "vicmd", IsCustom: false},
+		{Display: "w", Description: "Forward one word", Type: "widget", Target: "vi-forward-word", Keymap: "vicmd", IsCustom: false},
+		{Display: "b", Description: "Back one word", Type: "widget", Target: "vi-backward-word", Keymap: "vicmd", IsCustom: false},
+		{Display: "e", Description: "End of word", Type: "widget", Target: "vi-forward-word-end", Keymap: "vicmd", IsCustom: false},
+		{Display: "W", Description: "Forward one WORD", Type: "widget", Target: "vi-forward-blank-word", Keymap: "vicmd", IsCustom: false},
+		{Display: "B", Description: "Back one WORD", Type: "widget", Target: "vi-backward-blank-word", Keymap: "vicmd", IsCustom: false},
+		{Display: "0", Description: "Beginning of the line", Type: "widget", Target: "vi-digit-or-beginning-of-line", Keymap: "vicmd", IsCustom: false},
+		{Display: "^", Description: "First non-blank character", Type: "widget", Target: "vi-first-non-blank", Keymap: "vicmd", IsCustom: false},
+		{Display: "$", Description: "End of the line", Type: "widget", Target: "vi-end-of-line", Keymap: "vicmd", IsCustom: false},
+		{Display: "gg", Description: "First line in history", Type: "widget", Target: "beginning-of-buffer-or-history", Keymap: "vicmd", IsCustom: false},
+		{Display: "G", Description: "Last line in history", Type: "widget", Target: "end-of-buffer-or-history", Keymap: "vicmd", IsCustom: false},
+		{Display: "f", Description: "Jump to next typed character", Type: "widget", Target: "vi-find-next-char", Keymap: "vicmd", IsCustom: false},
+		{Display: "F", Description: "Jump to prev typed character", Type: "widget", Target: "vi-find-prev-char", Keymap: "vicmd", IsCustom: false},
+		{Display: ";", Description: "Repeat last find", Type: "widget", Target: "vi-repeat-find", Keymap: "vicmd", IsCustom: false},
+		{Display: "%", Description: "Jump to matching bracket", Type: "widget", Target: "vi-match-bracket", Keymap: "vicmd", IsCustom: false},
+		{Display: "i", Description: "Insert before cursor", Type: "widget", Target: "vi-insert", Keymap: "vicmd", IsCustom: false},
+		{Display: "a", Description: "Insert after cursor", Type: "widget", Target: "vi-add-next", Keymap: "vicmd", IsCustom: false},
+		{Display: "I", Description: "Insert at beginning of line", Type: "widget", Target: "vi-insert-bol", Keymap: "vicmd", IsCustom: false},
+		{Display: "A", Description: "Insert at end of line", Type: "widget", Target: "vi-add-eol", Keymap: "vicmd", IsCustom: false},
+		{Display: "x", Description: "Delete character under cursor", Type: "widget", Target: "vi-delete-char", Keymap: "vicmd", IsCustom: false},
+		{Display: "X", Description: "Kill one character backward", Type: "widget", Target: "vi-backward-delete-char", Keymap: "vicmd", IsCustom: false},
+		{Display: "d", Description: "Delete (with motion)", Type: "widget", Target: "vi-delete", Keymap: "vicmd", IsCustom: false},
+		{Display: "D", Description: "Kill to end of line", Type: "widget", Target: "vi-kill-eol", Keymap: "vicmd", IsCustom: false},
+		{Display: "c", Description: "Change (with motion)", Type: "widget", Target: "vi-change", Keymap: "vicmd", IsCustom: false},
+		{Display: "C", Description: "Change to end of line", Type: "widget", Target: "vi-change-eol", Keymap: "vicmd", IsCustom: false},
+		{Display: "y", Description: "Yank (with motion)", Type: "widget", Target: "vi-yank", Keymap: "vicmd", IsCustom: false},
+		{Display: "p", Description: "Paste after cursor", Type: "widget", Target: "vi-put-after", Keymap: "vicmd", IsCustom: false},
+		{Display: "P", Description: "Paste before cursor", Type: "widget", Target: "vi-put-before", Keymap: "vicmd", IsCustom: false},
+		{Display: "r", Description: "Replace character", Type: "widget", Target: "vi-replace-chars", Keymap: "vicmd", IsCustom: false},
+		{Display: "~", Description: "Swap case", Type: "widget", Target: "vi-swap-case", Keymap: "vicmd", IsCustom: false},
+		{Display: "u", Description: "Undo", Type: "widget", Target: "undo", Keymap: "vicmd", IsCustom: false},
+		{Display: "Ctrl+R", Description: "Redo", Type: "widget", Target: "redo", Keymap: "vicmd", IsCustom: false},
+		{Display: ".", Description: "Repeat last change", Type: "widget", Target: "vi-repeat-change", Keymap: "vicmd", IsCustom: false},
+		{Display: "/", Description: "Search history backward", Type: "widget", Target: "vi-history-search-backward", Keymap: "vicmd", IsCustom: false},
+		{Display: "?", Description: "Search history forward", Type: "widget", Target: "vi-history-search-forward", Keymap: "vicmd", IsCustom: false},
+		{Display: "n", Description: "Repeat search", Type: "widget", Target: "vi-repeat-search", Keymap: "vicmd", IsCustom: false},
+		{Display: "N", Description: "Repeat search reversed", Type: "widget", Target: "vi-rev-repeat-search", Keymap: "vicmd", IsCustom: false},
+		{Display: "v", Description: "Start visual mode", Type: "widget", Target: "visual-mode", Keymap: "vicmd", IsCustom: false},
+		{Display: "V", Description: "Start visual line mode", Type: "widget", Target: "visual-line-mode", Keymap: "vicmd", IsCustom: false},
+		{Display: "o", Description: "Swap cursor and selection end", Type: "widget", Target: "exchange-point-and-mark", Keymap: "visual", IsCustom: false},
+		{Display: "p", Description: "Replace selection with paste", Type: "widget", Target: "put-replace-selection", Keymap: "visual", IsCustom: false},
+		{Display: "u", Description: "Lowercase selection", Type: "widget", Target: "vi-down-case", Keymap: "visual", IsCustom: false},
+		{Display: "U", Description: "Uppercase selection", Type: "widget", Target: "vi-up-case", Keymap: "visual", IsCustom: false},
+		{Display: "x", Description: "Delete selection", Type: "widget", Target: "vi-delete", Keymap: "visual", IsCustom: false},
+		{Display: "~", Description: "Swap case of selection", Type: "widget", Target: "vi-oper-swap-case", Keymap: "visual", IsCustom: false},
+		{Display: "aw", Description: "Select a word", Type: "widget", Target: "select-a-word", Keymap: "visual", IsCustom: false},
+		{Display: "iw", Description: "Select inner word", Type: "widget", Target: "select-in-word", Keymap: "visual", IsCustom: false},
+		{Display: "Esc", Description: "Leave visual mode", Type: "widget", Target: "deactivate-region", Keymap: "visual", IsCustom: false},
+	}
+}
+
+func getBashBuiltinShortcuts() []Shortcut {
 	return []Shortcut{
 		{Display: "Ctrl+A", Description: "Beginning of the line", Type: "widget", Target: "beginning-of-line", IsCustom: false},
 		{Display: "Ctrl+E", Description: "End of the line", Type: "widget", Target: "end-of-line", IsCustom: false},
@@ -116,32 +331,43 @@ func getZshBuiltinShortcuts() []Shortcut {
 		{Display: "Alt+B", Description: "Back one word", Type: "widget", Target: "backward-word", IsCustom: false},
 		{Display: "Ctrl+T", Description: "Swap cursor with prev character", Type: "widget", Target: "transpose-chars", IsCustom: false},
 		{Display: "Alt+T", Description: "Swap cursor with prev word", Type: "widget", Target: "transpose-words", IsCustom: false},
-		{Display: "Ctrl+U", Description: "Clear to beginning of line", Type: "widget", Target: "backward-kill-line", IsCustom: false},
+		{Display: "Ctrl+U", Description: "Clear to beginning of line", Type: "widget", Target: "unix-line-discard", IsCustom: false},
 		{Display: "Ctrl+K", Description: "Kill to end of line", Type: "widget", Target: "kill-line", IsCustom: false},
-		{Display: "Ctrl+H", Description: "Kill one character backward", Type: "widget", Target: "backward-delete-char", IsCustom: false},
-		{Display: "Ctrl+W", Description: "Kill word back (if no Mark)", Type: "widget", Target: "backward-kill-word", IsCustom: false},
-		{Display: "Ctrl+@", Description: "Set Mark", Type: "widget", Target: "set-mark-command", IsCustom: false},
+		{Display: "Ctrl+W", Description: "Kill word back to whitespace", Type: "widget", Target: "unix-word-rubout", IsCustom: false},
+		{Display: "Alt+D", Description: "Kill word forward", Type: "widget", Target: "kill-word", IsCustom: false},
+		{Display: "Alt+Backspace", Description: "Kill word backward", Type: "widget", Target: "backward-kill-word", IsCustom: false},
 		{Display: "Ctrl+Y", Description: "Paste from Kill Ring", Type: "widget", Target: "yank", IsCustom: false},
+		{Display: "Alt+Y", Description: "Cycle Kill Ring", Type: "widget", Target: "yank-pop", IsCustom: false},
+		{Display: "Ctrl+@", Description: "Set Mark", Type: "widget", Target: "set-mark", IsCustom: false},
+		{Display: "Ctrl+X Ctrl+X", Description: "Swap cursor and Mark", Type: "widget", Target: "exchange-point-and-mark", IsCustom: false},
 		{Display: "Ctrl+V", Description: "Quoted insert", Type: "widget", Target: "quoted-insert", IsCustom: false},
-		{Display: "Ctrl+Q", Description: "Push line to be used again", Type: "widget", Target: "push-line", IsCustom: false},
 		{Display: "Ctrl+_", Description: "Undo", Type: "widget", Target: "undo", IsCustom: false},
-		{Display: "Ctrl+P", Description: "Prev line", Type: "widget", Target: "up-line-or-history", IsCustom: false},
-		{Display: "Ctrl+N", Description: "Next Line", Type: "widget", Target: "down-line-or-history", IsCustom: false},
-		{Display: "Ctrl+R", Description: "Search", Type: "widget", Target: "history-incremental-search-backward", IsCustom: false},
-		{Display: "Alt+P", Description: "Match word on line", Type: "widget", Target: "history-search-backward", IsCustom: false},
-		{Display: "Alt+.", Description: "Extract last word", Type: "widget", Target: "insert-last-word", IsCustom: false},
+		{Display: "Ctrl+P", Description: "Prev line", Type: "widget", Target: "previous-history", IsCustom: false},
+		{Display: "Ctrl+N", Description: "Next Line", Type: "widget", Target: "next-history", IsCustom: false},
+		{Display: "Ctrl+R", Description: "Search", Type: "widget", Target: "reverse-search-history", IsCustom: false},
+		{Display: "Ctrl+S", Description: "Search forward", Type: "widget", Target: "forward-search-history", IsCustom: false},
+		{Display: "Alt+<", Description: "First line in history", Type: "widget", Target: "beginning-of-history", IsCustom: false},
+		{Display: "Alt+>", Description: "Last line in history", Type: "widget", Target: "end-of-history", IsCustom: false},
+		{Display: "Alt+.", Description: "Extract last word", Type: "widget", Target: "yank-last-arg", IsCustom: false},
+		{Display: "Alt+U", Description: "Uppercase word", Type: "widget", Target: "upcase-word", IsCustom: false},
+		{Display: "Alt+L", Description: "Lowercase word", Type: "widget", Target: "downcase-word", IsCustom: false},
+		{Display: "Alt+C", Description: "Capitalize word", Type: "widget", Target: "capitalize-word", IsCustom: false},
+		{Display: "Alt+#", Description: "Comment out line and execute", Type: "widget", Target: "insert-comment", IsCustom: false},
+		{Display: "Ctrl+Alt+E", Description: "Expand aliases and history", Type: "widget", Target: "shell-expand-line", IsCustom: false},
 		{Display: "Ctrl+L", Description: "Clear screen", Type: "widget", Target: "clear-screen", IsCustom: false},
-		{Display: "Ctrl+S", Description: "Stop screen output", Type: "sequence", Target: "C-s", IsCustom: false},
 		{Display: "Ctrl+C", Description: "Kill proc", Type: "sequence", Target: "C-c", IsCustom: false},
 		{Display: "Ctrl+Z", Description: "Suspend proc", Type: "sequence", Target: "C-z", IsCustom: false},
-		{Display: "Ctrl+O", Description: "Exec cmd but keep line", Type: "widget", Target: "accept-line-and-down-history", IsCustom: false},
-		{Display: "Tab", Description: "Complete command/filename", Type: "widget", Target: "expand-or-complete", IsCustom: false},
+		{Display: "Ctrl+O", Description: "Exec cmd and fetch next history line", Type: "widget", Target: "operate-and-get-next", IsCustom: false},
+		{Display: "Tab", Description: "Complete command/filename", Type: "widget", Target: "complete", IsCustom: false},
+		{Display: "Alt+?", Description: "List completions", Type: "widget", Target: "possible-completions", IsCustom: false},
+		{Display: "Alt+*", Description: "Insert all completions", Type: "widget", Target: "insert-completions", IsCustom: false},
 		{Display: "Enter", Description: "Execute command", Type: "widget", Target: "accept-line", IsCustom: false},
-		{Display: "Ctrl+D", Description: "Delete character or EOF", Type: "widget", Target: "delete-char-or-list", IsCustom: false},
-		{Display: "Ctrl+G", Description: "Abort current operation", Type: "widget", Target: "send-break", IsCustom: false},
-		{Display: "Ctrl+X Ctrl+E", Description: "Edit command in editor", Type: "widget", Target: "edit-command-line", IsCustom: false},
-		{Display: "↑", Description: "Previous command in history", Type: "widget", Target: "up-line-or-history", IsCustom: false},
-		{Display: "↓", Description: "Next command in history", Type: "widget", Target: "down-line-or-history", IsCustom: false},
+		{Display: "Ctrl+D", Description: "Delete character or EOF", Type: "widget", Target: "delete-char", IsCustom: false},
+		{Display: "Ctrl+G", Description: "Abort current operation", Type: "widget", Target: "abort", IsCustom: false},
+		{Display: "Ctrl+]", Description: "Jump to next typed character", Type: "widget", Target: "character-search", IsCustom: false},
+		{Display: "Ctrl+X Ctrl+E", Description: "Edit command in editor", Type: "widget", Target: "edit-and-execute-command", IsCustom: false},
+		{Display: "↑", Description: "Previous command in history", Type: "widget", Target: "previous-history", IsCustom: false},
+		{Display: "↓", Description: "Next command in history", Type: "widget", Target: "next-history", IsCustom: false},
 		{Display: "←", Description: "Move cursor left", Type: "widget", Target: "backward-char", IsCustom: false},
 		{Display: "→", Description: "Move cursor right", Type: "widget", Target: "forward-char", IsCustom: false},
 		{Display: "Home", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", IsCustom: false},
@@ -149,66 +375,82 @@ func getZshBuiltinShortcuts() []Shortcut {
 	}
 }
 
+func getFishBuiltinShortcuts() []Shortcut {
+	return []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of the line", Type: "widget", Target: "beginning-of-line", IsCustom: false},
+		{Display: "Ctrl+E", Description: "End of the line", Type: "widget", Target: "end-of-line", IsCustom: false},
+		{Display: "Ctrl+F", Description: "Forward one character", Type: "widget", Target: "forward-char", IsCustom: false},
+		{Display: "Ctrl+B", Description: "Back one character", Type: "widget", Target: "backward-char", IsCustom: false},
+		{Display: "Alt+F", Description: "Forward one word", Type: "widget", Target: "nextd-or-forward-word", IsCustom: false},
+		{Display: "Alt+B", Description: "Back one word", Type: "widget", Target: "prevd-or-backward-word", IsCustom: false},
+		{Display: "Ctrl+T", Description: "Swap cursor with prev character", Type: "widget", Target: "transpose-chars", IsCustom: false},
+		{Display: "Alt+T", Description: "Swap cursor with prev word", Type: "widget", Target: "transpose-words", IsCustom: false},
+		{Display: "Ctrl+U", Description: "Clear to beginning of line", Type: "widget", Target: "backward-kill-line", IsCustom: false},
+		{Display: "Ctrl+K", Description: "Kill to end of line", Type: "widget", Target: "kill-line", IsCustom: false},
+		{Display: "Ctrl+W", Description: "Kill path component back", Type: "widget", Target: "backward-kill-path-component", IsCustom: false},
+		{Display: "Alt+D", Description: "Kill word forward", Type: "widget", Target: "kill-word", IsCustom: false},
+		{Display: "Alt+Backspace", Description: "Kill word backward", Type: "widget", Target: "backward-kill-word", IsCustom: false},
+		{Display: "Ctrl+Y", Description: "Paste from Kill Ring", Type: "widget", Target: "yank", IsCustom: false},
+		{Display: "Alt+Y", Description: "Cycle Kill Ring", Type: "widget", Target: "yank-pop", IsCustom: false},
+		{Display: "Ctrl+Z", Description: "Undo", Type: "widget", Target: "undo", IsCustom: false},
+		{Display: "Ctrl+P", Description: "Prev line", Type: "widget", Target: "up-or-search", IsCustom: false},
+		{Display: "Ctrl+N", Description: "Next Line", Type: "widget", Target: "down-or-search", IsCustom: false},
+		{Display: "Ctrl+R", Description: "Search", Type: "widget", Target: "history-pager", IsCustom: false},
+		{Display: "Alt+.", Description: "Extract last word", Type: "widget", Target: "history-token-search-backward", IsCustom: false},
+		{Display: "Alt+U", Description: "Uppercase word", Type: "widget", Target: "upcase-word", IsCustom: false},
+		{Display: "Alt+C", Description: "Capitalize word", Type: "widget", Target: "capitalize-word", IsCustom: false},
+		{Display: "Alt+E", Description: "Edit command in editor", Type: "widget", Target: "edit_command_buffer", IsCustom: false},
+		{Display: "Alt+H", Description: "Show man page for command", Type: "widget", Target: "__fish_man_page", IsCustom: false},
+		{Display: "Alt+W", Description: "Describe current command", Type: "widget", Target: "__fish_whatis_current_token", IsCustom: false},
+		{Display: "Alt+L", Description: "List files in current token", Type: "widget", Target: "__fish_list_current_token", IsCustom: false},
+		{Display: "Alt+P", Description: "Pipe command into pager", Type: "widget", Target: "__fish_paginate", IsCustom: false},
+		{Display: "Alt+#", Description: "Toggle comment on line", Type: "widget", Target: "__fish_toggle_comment_commandline", IsCustom: false},
+		{Display: "Ctrl+X", Description: "Copy line to clipboard", Type: "widget", Target: "fish_clipboard_copy", IsCustom: false},
+		{Display: "Ctrl+V", Description: "Paste from clipboard", Type: "widget", Target: "fish_clipboard_paste", IsCustom: false},
+		{Display: "Ctrl+L", Description: "Clear screen", Type: "widget", Target: "clear-screen", IsCustom: false},
+		{Display: "Ctrl+C", Description: "Cancel current command line", Type: "widget", Target: "cancel-commandline", IsCustom: false},
+		{Display: "Ctrl+D", Description: "Delete character or exit", Type: "widget", Target: "delete-or-exit", IsCustom: false},
+		{Display: "Tab", Description: "Complete command/filename", Type: "widget", Target: "complete", IsCustom: false},
+		{Display: "Shift+Tab", Description: "Complete and search completions", Type: "widget", Target: "complete-and-search", IsCustom: false},
+		{Display: "Enter", Description: "Execute command", Type: "widget", Target: "execute", IsCustom: false},
+		{Display: "↑", Description: "Previous command in history", Type: "widget", Target: "up-or-search", IsCustom: false},
+		{Display: "↓", Description: "Next command in history", Type: "widget", Target: "down-or-search", IsCustom: false},
+		{Display: "←", Description: "Move cursor left", Type: "widget", Target: "backward-char", IsCustom: false},
+		{Display: "→", Description: "Accept autosuggestion", Type: "widget", Target: "forward-char", IsCustom: false},
+		{Display: "Home", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", IsCustom: false},
+		{Display: "End", Description: "End of line", Type: "widget", Target: "end-of-line", IsCustom: false},
+	}
+}
+
+// normalizeKey canonicalizes a key written in any notation the config
+// accepts, so "^X^E", "C-x C-e" and "ctrl+x ctrl+e" all become
+// "Ctrl+X Ctrl+E". Keys that don't parse only have their modifier names
+// capitalized.
 func normalizeKey(key string) string {
 	key = strings.TrimSpace(key)
-	if matched, _ := regexp.MatchString(`^\^[A-Za-z@_\[\]\\]$`, key); matched {
-		char := strings.ToUpper(string(key[1]))
-		switch char {
-		case "[":
-			return "Esc"
-		case "I":
-			return "Tab"
-		case "M":
-			return "Enter"
-		case "H":
-			return "Backspace"
-		case "@":
-			return "Ctrl+@"
-		case "_":
-			return "Ctrl+_"
-		case "\\":
-			return "Ctrl+\\"
-		case "]":
-			return "Ctrl+]"
-		default:
-			return "Ctrl+" + char
-		}
+	if sequence, err := ParseKeySequence(key); err == nil {
+		return sequence.Display()
 	}
-	if matched, _ := regexp.MatchString(`^[Cc]-[a-zA-Z@_\[\]\\]$`, key); matched {
-		char := strings.ToUpper(string(key[2]))
-		return "Ctrl+" + char
-	}
-	if matched, _ := regexp.MatchString(`^[Mm]-[a-zA-Z]$`, key); matched {
-		char := strings.ToUpper(string(key[2]))
-		return "Alt+" + char
-	}
-	key = regexp.MustCompile(`(?i)ctrl\+`).ReplaceAllString(key, "Ctrl+")
-	key = regexp.MustCompile(`(?i)alt\+`).ReplaceAllString(key, "Alt+")
-	key = regexp.MustCompile(`(?i)shift\+`).ReplaceAllString(key, "Shift+")
-	key = regexp.MustCompile(`(?i)meta\+`).ReplaceAllString(key, "Alt+")
-
-	parts := strings.Split(key, "+")
-	if len(parts) > 1 {
-		lastPart := parts[len(parts)-1]
-		if len(lastPart) == 1 && lastPart >= "a" && lastPart <= "z" {
-			parts[len(parts)-1] = strings.ToUpper(lastPart)
-		} else if strings.ToLower(lastPart) == "tab" {
-			parts[len(parts)-1] = "Tab"
+	return modifierNamePattern.ReplaceAllStringFunc(key, func(modifier string) string {
+		switch strings.ToLower(modifier) {
+		case "ctrl+":
+			return "Ctrl+"
+		case "shift+":
+			return "Shift+"
+		default:
+			return "Alt+"
 		}
-		key = strings.Join(parts, "+")
-	}
-
-	return key
+	})
 }
 
+var modifierNamePattern = regexp.MustCompile(`(?i)(ctrl|alt|meta|shift)\+`)
+
 func loadConfig() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
 		return &Config{Shortcuts: make(map[string]interface{})}, nil
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "shortcutter", "config.toml")
-
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return &Config{Shortcuts: make(map[string]interface{})}, nil
 	}
@@ -228,9 +470,52 @@ func loadConfig() (*Config, error) {
 func mergeShortcuts(builtins []Shortcut, config *Config) []Shortcut {
 	shortcutMap := make(map[string]Shortcut)
 	
-	// Index built-ins by their display name
+	// Index built-ins by their keymap and display name
 	for _, shortcut := range builtins {
-		normalizedKey := normalizeKey(shortcut.Display)
+		normalizedKey := mergeKey(shortcut.Keymap, shortcut.Display)
+		shortcutMap[normalizedKey] = shortcut
+	}
+
+	// mergeTable applies a full object configuration, optionally targeting a
+	// specific keymap
+	mergeTable := func(configKey string, v map[string]interface{}) {
+		keymap, _ := v["keymap"].(string)
+		display := normalizeKey(configKey)
+		normalizedKey := mergeKey(keymap, configKey)
+
+		keys, _ := ParseKeySequence(configKey)
+		shortcut := Shortcut{
+			Display:  display,
+			Keys:     keys,
+			Keymap:   keymap,
+			IsCustom: true,
+			Category: "custom",
+			Source:   "config",
+		}
+
+		// Start with existing built-in if it exists
+		if existing, exists := shortcutMap[normalizedKey]; exists {
+			shortcut = existing
+			shortcut.IsCustom = true
+		}
+
+		// Override with config values
+		if display, ok := v["display"].(string); ok {
+			shortcut.Display = display
+		}
+		if description, ok := v["description"].(string); ok {
+			shortcut.Description = description
+		}
+		if shortcutType, ok := v["type"].(string); ok {
+			shortcut.Type = shortcutType
+		}
+		if target, ok := v["target"].(string); ok {
+			shortcut.Target = target
+		}
+		if category, ok := v["category"].(string); ok {
+			shortcut.Category = category
+		}
+
 		shortcutMap[normalizedKey] = shortcut
 	}
 
@@ -253,44 +538,27 @@ func mergeShortcuts(builtins []Shortcut, config *Config) []Shortcut {
 					shortcutMap[normalizedKey] = existing
 				} else {
 					// New shortcut with just description - assume it's a command
+					keys, _ := ParseKeySequence(configKey)
 					shortcut := Shortcut{
 						Display:     normalizedKey,
+						Keys:        keys,
 						Description: v,
 						Type:        "command",
 						Target:      v, // Use description as command for simple cases
 						IsCustom:    true,
+						Category:    "custom",
+						Source:      "config",
 					}
 					shortcutMap[normalizedKey] = shortcut
 				}
 			}
 		case map[string]interface{}:
-			// Full object configuration
-			shortcut := Shortcut{
-				Display:  normalizedKey,
-				IsCustom: true,
-			}
-			
-			// Start with existing built-in if it exists
-			if existing, exists := shortcutMap[normalizedKey]; exists {
-				shortcut = existing
-				shortcut.IsCustom = true
-			}
-			
-			// Override with config values
-			if display, ok := v["display"].(string); ok {
-				shortcut.Display = display
-			}
-			if description, ok := v["description"].(string); ok {
-				shortcut.Description = description
+			mergeTable(configKey, v)
+		case []map[string]interface{}:
+			// [[shortcuts.key]] tables configure one key in several keymaps
+			for _, table := range v {
+				mergeTable(configKey, table)
 			}
-			if shortcutType, ok := v["type"].(string); ok {
-				shortcut.Type = shortcutType
-			}
-			if target, ok := v["target"].(string); ok {
-				shortcut.Target = target
-			}
-			
-			shortcutMap[normalizedKey] = shortcut
 		}
 	}
 
@@ -300,12 +568,50 @@ func mergeShortcuts(builtins []Shortcut, config *Config) []Shortcut {
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Display < result[j].Display
+		if result[i].Display != result[j].Display {
+			return result[i].Display < result[j].Display
+		}
+		return result[i].Keymap < result[j].Keymap
 	})
 
 	return result
 }
 
+// mergeKey identifies a shortcut across built-ins and config. Emacs and
+// keymap-independent entries share the plain normalized key, so config
+// entries without a keymap apply to them; other keymaps are prefixed.
+func mergeKey(keymap string, display string) string {
+	if keymap == "" || keymap == "emacs" {
+		return normalizeKey(display)
+	}
+	return keymap + " " + normalizeKey(display)
+}
+
+// KeyLabel renders the shortcut's key in the given key style. The default
+// style, and shortcuts without keys such as aliases, keep their Display.
+func (s Shortcut) KeyLabel(style string) string {
+	if s.Keys == nil || style == "" || style == "default" {
+		return s.Display
+	}
+	return s.Keys.Format(style)
+}
+
+// FindShortcuts returns the shortcuts whose key matches key in any notation
+// the config accepts, limited to keymap when it is not empty.
+func FindShortcuts(shortcuts []Shortcut, key string, keymap string) []Shortcut {
+	var matches []Shortcut
+	for _, shortcut := range shortcuts {
+		if normalizeKey(shortcut.Display) != normalizeKey(key) {
+			continue
+		}
+		if keymap != "" && shortcut.Keymap != keymap {
+			continue
+		}
+		matches = append(matches, shortcut)
+	}
+	return matches
+}
+
 func DetectShortcuts() ([]Shortcut, error) {
 	return LoadShortcuts()
 }
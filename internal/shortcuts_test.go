@@ -16,7 +16,10 @@ func TestNormalizeKey(t *testing.T) {
 		{"^[", "Esc"},
 		{"^I", "Tab"},
 		{"^M", "Enter"},
-		{"^H", "Backspace"},
+		// ^H and ^? are different bytes with different bindings, see
+		// singleByteKeys
+		{"^H", "Ctrl+H"},
+		{"^?", "Backspace"},
 		{"^@", "Ctrl+@"},
 		{"^_", "Ctrl+_"},
 
@@ -77,8 +80,9 @@ func TestDetectShell(t *testing.T) {
 	}{
 		{"/bin/zsh", "zsh", false},
 		{"/usr/bin/zsh", "zsh", false},
-		{"/bin/bash", "", true},
-		{"/usr/bin/fish", "", true},
+		{"/bin/bash", "bash", false},
+		{"/usr/local/bin/bash", "bash", false},
+		{"/usr/bin/fish", "fish", false},
 		{"/bin/unknown", "", true},
 		{"", "", true},
 	}
@@ -140,9 +144,17 @@ func TestGetBuiltinShortcuts(t *testing.T) {
 		}
 	}
 
-	_, err = getBuiltinShortcuts("bash")
+	bashShortcuts, err := getBuiltinShortcuts("bash")
+	if err != nil {
+		t.Errorf("getBuiltinShortcuts(\"bash\") returned error: %v", err)
+	}
+	if len(bashShortcuts) == 0 {
+		t.Error("getBuiltinShortcuts(\"bash\") returned empty slice")
+	}
+
+	_, err = getBuiltinShortcuts("tcsh")
 	if err == nil {
-		t.Error("getBuiltinShortcuts(\"bash\") should return error")
+		t.Error("getBuiltinShortcuts(\"tcsh\") should return error")
 	}
 }
 
@@ -258,6 +270,16 @@ func TestLoadShortcuts(t *testing.T) {
 
 	getShellEnv = func() string { return "/bin/bash" }
 
+	shortcuts, err = LoadShortcuts()
+	if err != nil {
+		t.Errorf("LoadShortcuts() with bash returned error: %v", err)
+	}
+	if len(shortcuts) == 0 {
+		t.Error("LoadShortcuts() with bash returned empty slice")
+	}
+
+	getShellEnv = func() string { return "/bin/tcsh" }
+
 	_, err = LoadShortcuts()
 	if err == nil {
 		t.Error("LoadShortcuts() should return error for unsupported shell")
@@ -373,6 +395,22 @@ func TestMergeShortcutsWithObjectConfig(t *testing.T) {
 	if !foundPartial {
 		t.Error("Partial override shortcut not found")
 	}
+
+	// Test category override of built-in
+	builtins[0].Category = "movement"
+	categoryConfig := &Config{
+		Shortcuts: map[string]interface{}{
+			"Ctrl+A": map[string]interface{}{
+				"category": "favourites",
+			},
+		},
+	}
+	result = mergeShortcuts(builtins, categoryConfig)
+	for _, shortcut := range result {
+		if shortcut.Display == "Ctrl+A" && shortcut.Category != "favourites" {
+			t.Errorf("Category override: got %q, want %q", shortcut.Category, "favourites")
+		}
+	}
 }
 
 func TestNormalizeKeyEdgeCases(t *testing.T) {
@@ -413,3 +451,86 @@ func TestNormalizeKeyEdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestZshViBuiltinShortcuts(t *testing.T) {
+	shortcuts := getZshViBuiltinShortcuts()
+	keymaps := make(map[string]int)
+	for _, shortcut := range shortcuts {
+		keymaps[shortcut.Keymap]++
+	}
+
+	for _, keymap := range []string{"viins", "vicmd", "visual"} {
+		if keymaps[keymap] == 0 {
+			t.Errorf("Vi catalog has no %s shortcuts", keymap)
+		}
+	}
+	if len(keymaps) != 3 {
+		t.Errorf("Vi catalog should only use viins, vicmd and visual keymaps, got %v", keymaps)
+	}
+}
+
+func TestMergeShortcutsWithKeymaps(t *testing.T) {
+	builtins := []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs"},
+		{Display: "x", Description: "Delete character", Type: "widget", Target: "vi-delete-char", Keymap: "vicmd"},
+		{Display: "x", Description: "Delete selection", Type: "widget", Target: "vi-delete", Keymap: "visual"},
+	}
+
+	config := &Config{
+		Shortcuts: map[string]interface{}{
+			"Ctrl+A": false,
+			"x": map[string]interface{}{
+				"keymap":      "vicmd",
+				"description": "Cut character",
+			},
+			"dd": map[string]interface{}{
+				"keymap":      "vicmd",
+				"description": "Delete line",
+				"type":        "widget",
+				"target":      "kill-whole-line",
+			},
+		},
+	}
+	result := mergeShortcuts(builtins, config)
+
+	if len(result) != 3 {
+		t.Fatalf("mergeShortcuts with keymaps: got %d shortcuts, want 3", len(result))
+	}
+
+	for _, shortcut := range result {
+		switch {
+		case shortcut.Display == "Ctrl+A":
+			t.Error("Ctrl+A should have been removed from the emacs keymap")
+		case shortcut.Display == "x" && shortcut.Keymap == "vicmd":
+			if shortcut.Description != "Cut character" || !shortcut.IsCustom {
+				t.Errorf("vicmd x should be overridden, got %+v", shortcut)
+			}
+		case shortcut.Display == "x" && shortcut.Keymap == "visual":
+			if shortcut.Description != "Delete selection" || shortcut.IsCustom {
+				t.Errorf("visual x should be untouched, got %+v", shortcut)
+			}
+		case shortcut.Display == "dd":
+			if shortcut.Keymap != "vicmd" || shortcut.Target != "kill-whole-line" {
+				t.Errorf("dd should be added to vicmd, got %+v", shortcut)
+			}
+		}
+	}
+}
+
+func TestFindShortcuts(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+A", Type: "widget", Target: "beginning-of-line", Keymap: "emacs"},
+		{Display: "Ctrl+A", Type: "widget", Target: "beginning-of-line", Keymap: "viins"},
+		{Display: "gs", Type: "command", Target: "git status"},
+	}
+
+	if matches := FindShortcuts(shortcuts, "^A", ""); len(matches) != 2 {
+		t.Errorf("FindShortcuts(^A) returned %d matches, want 2", len(matches))
+	}
+	if matches := FindShortcuts(shortcuts, "C-a", "viins"); len(matches) != 1 || matches[0].Keymap != "viins" {
+		t.Errorf("FindShortcuts(C-a, viins) = %+v", matches)
+	}
+	if matches := FindShortcuts(shortcuts, "gd", ""); len(matches) != 0 {
+		t.Errorf("FindShortcuts(gd) = %+v, want none", matches)
+	}
+}
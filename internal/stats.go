@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"sort"
+	"time"
+)
+
+// UsageStats summarizes the usage log for `shortcutter stats`.
+type UsageStats struct {
+	Total  int              `json:"total"`
+	First  *time.Time       `json:"first,omitempty"` // Earliest selection, nil for an empty log
+	Last   *time.Time       `json:"last,omitempty"`
+	Top    []ShortcutUsage  `json:"top"`
+	Weeks  []PeriodUsage    `json:"weeks"`
+	Unused []UnusedShortcut `json:"unused"`
+}
+
+// ShortcutUsage is how often one shortcut was picked.
+type ShortcutUsage struct {
+	Key      string    `json:"key"`
+	Keymap   string    `json:"keymap,omitempty"`
+	Type     string    `json:"type"`
+	Target   string    `json:"target"`
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// UnusedShortcut is a built-in shortcut that was never picked.
+type UnusedShortcut struct {
+	Key         string `json:"key"`
+	Keymap      string `json:"keymap,omitempty"`
+	Description string `json:"description"`
+}
+
+// PeriodUsage counts the selections in the week starting on Start.
+type PeriodUsage struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// UsageReport reads the usage log and summarizes it: the top most picked
+// shortcuts, selections per week over the last weeks, and the built-in
+// shortcuts of the current shell that were never picked.
+func UsageReport(top int, weeks int) (UsageStats, error) {
+	events, err := LoadUsageLog()
+	if err != nil {
+		return UsageStats{}, err
+	}
+
+	shell, err := detectShell()
+	if err != nil {
+		shell = "zsh"
+	}
+	builtins, err := builtinCatalog(shell)
+	if err != nil {
+		return UsageStats{}, err
+	}
+
+	return computeUsageStats(events, builtins, top, weeks, time.Now()), nil
+}
+
+// builtinCatalog returns the built-in shortcuts checked for unused entries.
+// For zsh that is the emacs catalog only, so vi bindings an emacs user never
+// touches don't bury the list.
+func builtinCatalog(shell string) ([]Shortcut, error) {
+	if shell == "zsh" {
+		return getZshBuiltinShortcuts(), nil
+	}
+	return getBuiltinShortcuts(shell)
+}
+
+func computeUsageStats(events []UsageEvent, builtins []Shortcut, top int, weeks int, now time.Time) UsageStats {
+	stats := UsageStats{Total: len(events)}
+
+	counts := make(map[string]*ShortcutUsage)
+	for i, event := range events {
+		if stats.First == nil || event.Time.Before(*stats.First) {
+			stats.First = &events[i].Time
+		}
+		if stats.Last == nil || event.Time.After(*stats.Last) {
+			stats.Last = &events[i].Time
+		}
+
+		key := mergeKey(event.Keymap, event.Key)
+		usage, ok := counts[key]
+		if !ok {
+			usage = &ShortcutUsage{Key: event.Key, Keymap: event.Keymap}
+			counts[key] = usage
+		}
+		usage.Count++
+		if !event.Time.Before(usage.LastUsed) {
+			usage.LastUsed = event.Time
+			usage.Type = event.Type
+			usage.Target = event.Target
+		}
+	}
+
+	stats.Top = []ShortcutUsage{}
+	for _, usage := range counts {
+		stats.Top = append(stats.Top, *usage)
+	}
+	sort.Slice(stats.Top, func(i, j int) bool {
+		if stats.Top[i].Count != stats.Top[j].Count {
+			return stats.Top[i].Count > stats.Top[j].Count
+		}
+		return stats.Top[i].LastUsed.After(stats.Top[j].LastUsed)
+	})
+	if top >= 0 && len(stats.Top) > top {
+		stats.Top = stats.Top[:top]
+	}
+
+	stats.Weeks = weeklyUsage(events, weeks, now)
+
+	stats.Unused = []UnusedShortcut{}
+	for _, shortcut := range builtins {
+		if _, ok := counts[mergeKey(shortcut.Keymap, shortcut.Display)]; !ok {
+			stats.Unused = append(stats.Unused, UnusedShortcut{
+				Key:         shortcut.Display,
+				Keymap:      shortcut.Keymap,
+				Description: shortcut.Description,
+			})
+		}
+	}
+
+	return stats
+}
+
+// weeklyUsage counts the selections in each of the last weeks, oldest first.
+// Weeks start on Monday.
+func weeklyUsage(events []UsageEvent, weeks int, now time.Time) []PeriodUsage {
+	if weeks <= 0 {
+		return []PeriodUsage{}
+	}
+
+	current := startOfWeek(now)
+	periods := make([]PeriodUsage, weeks)
+	for i := range periods {
+		periods[i].Start = current.AddDate(0, 0, -7*(weeks-1-i))
+	}
+
+	for _, event := range events {
+		start := startOfWeek(event.Time.In(now.Location()))
+		index := weeks - 1 - int(current.Sub(start).Hours()/24/7+0.5)
+		if index >= 0 && index < weeks {
+			periods[index].Count++
+		}
+	}
+	return periods
+}
+
+// startOfWeek returns midnight on the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeUsageStats(t *testing.T) {
+	// A Thursday, so the current week started on Monday the 12th
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	events := []UsageEvent{
+		{Key: "Ctrl+R", Keymap: "emacs", Type: "widget", Target: "history-incremental-search-backward", Time: now.AddDate(0, 0, -20)},
+		{Key: "gs", Type: "command", Target: "git status", Time: now.AddDate(0, 0, -8)},
+		{Key: "Ctrl+R", Type: "widget", Target: "history-incremental-search-backward", Time: now.AddDate(0, 0, -2)},
+		{Key: "Ctrl+R", Keymap: "emacs", Type: "widget", Target: "history-incremental-search-backward", Time: now.AddDate(0, 0, -1)},
+		{Key: "Ctrl+E", Keymap: "emacs", Type: "widget", Target: "end-of-line", Time: now.AddDate(0, 0, -1)},
+	}
+	builtins := []Shortcut{
+		{Display: "Ctrl+A", Keymap: "emacs", Description: "Beginning of the line"},
+		{Display: "Ctrl+E", Keymap: "emacs", Description: "End of the line"},
+		{Display: "Ctrl+R", Keymap: "emacs", Description: "Search history"},
+	}
+
+	stats := computeUsageStats(events, builtins, 2, 4, now)
+
+	if stats.Total != 5 {
+		t.Errorf("Total: got %d, want 5", stats.Total)
+	}
+	if stats.First == nil || !stats.First.Equal(now.AddDate(0, 0, -20)) {
+		t.Errorf("First: got %v, want %v", stats.First, now.AddDate(0, 0, -20))
+	}
+
+	if len(stats.Top) != 2 {
+		t.Fatalf("Top: got %d entries, want 2", len(stats.Top))
+	}
+	if stats.Top[0].Key != "Ctrl+R" || stats.Top[0].Count != 3 {
+		t.Errorf("Top[0]: got %s x%d, want Ctrl+R x3", stats.Top[0].Key, stats.Top[0].Count)
+	}
+	if stats.Top[1].Key != "Ctrl+E" {
+		t.Errorf("Top[1]: got %s, want the more recent Ctrl+E", stats.Top[1].Key)
+	}
+
+	expectedWeeks := []int{1, 0, 1, 3}
+	if len(stats.Weeks) != len(expectedWeeks) {
+		t.Fatalf("Weeks: got %d, want %d", len(stats.Weeks), len(expectedWeeks))
+	}
+	for i, count := range expectedWeeks {
+		if stats.Weeks[i].Count != count {
+			t.Errorf("Week of %s: got %d, want %d", stats.Weeks[i].Start.Format("2006-01-02"), stats.Weeks[i].Count, count)
+		}
+	}
+	if start := stats.Weeks[3].Start; !start.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Current week should start on Monday, got %v", start)
+	}
+
+	if len(stats.Unused) != 1 || stats.Unused[0].Key != "Ctrl+A" {
+		t.Errorf("Unused: got %+v, want only Ctrl+A", stats.Unused)
+	}
+}
+
+func TestComputeUsageStatsEmpty(t *testing.T) {
+	stats := computeUsageStats(nil, getZshBuiltinShortcuts(), 10, 0, time.Now())
+	if stats.Total != 0 || stats.First != nil || len(stats.Top) != 0 || len(stats.Weeks) != 0 {
+		t.Errorf("Empty log: got %+v", stats)
+	}
+	if len(stats.Unused) != len(getZshBuiltinShortcuts()) {
+		t.Errorf("Empty log should leave every built-in unused, got %d", len(stats.Unused))
+	}
+}
@@ -0,0 +1,240 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xo/terminfo"
+)
+
+// terminalKeyCaps maps key names to the terminfo capabilities holding the
+// sequence the terminal sends for them.
+var terminalKeyCaps = map[string]int{
+	"up":       terminfo.KeyUp,
+	"↑":        terminfo.KeyUp,
+	"down":     terminfo.KeyDown,
+	"↓":        terminfo.KeyDown,
+	"right":    terminfo.KeyRight,
+	"→":        terminfo.KeyRight,
+	"left":     terminfo.KeyLeft,
+	"←":        terminfo.KeyLeft,
+	"home":     terminfo.KeyHome,
+	"end":      terminfo.KeyEnd,
+	"insert":   terminfo.KeyIc,
+	"delete":   terminfo.KeyDc,
+	"prior":    terminfo.KeyPpage,
+	"pageup":   terminfo.KeyPpage,
+	"next":     terminfo.KeyNpage,
+	"pagedown": terminfo.KeyNpage,
+	"backtab":  terminfo.KeyBtab,
+	"f1":       terminfo.KeyF1,
+	"f2":       terminfo.KeyF2,
+	"f3":       terminfo.KeyF3,
+	"f4":       terminfo.KeyF4,
+	"f5":       terminfo.KeyF5,
+	"f6":       terminfo.KeyF6,
+	"f7":       terminfo.KeyF7,
+	"f8":       terminfo.KeyF8,
+	"f9":       terminfo.KeyF9,
+	"f10":      terminfo.KeyF10,
+	"f11":      terminfo.KeyF11,
+	"f12":      terminfo.KeyF12,
+}
+
+// xtermKeys are the sequences an xterm-compatible terminal sends in normal
+// cursor mode, used when terminfo is unavailable.
+var xtermKeys = map[int]string{
+	terminfo.KeyUp:    "\x1b[A",
+	terminfo.KeyDown:  "\x1b[B",
+	terminfo.KeyRight: "\x1b[C",
+	terminfo.KeyLeft:  "\x1b[D",
+	terminfo.KeyHome:  "\x1b[H",
+	terminfo.KeyEnd:   "\x1b[F",
+	terminfo.KeyIc:    "\x1b[2~",
+	terminfo.KeyDc:    "\x1b[3~",
+	terminfo.KeyPpage: "\x1b[5~",
+	terminfo.KeyNpage: "\x1b[6~",
+	terminfo.KeyBtab:  "\x1b[Z",
+	terminfo.KeyF1:    "\x1bOP",
+	terminfo.KeyF2:    "\x1bOQ",
+	terminfo.KeyF3:    "\x1bOR",
+	terminfo.KeyF4:    "\x1bOS",
+	terminfo.KeyF5:    "\x1b[15~",
+	terminfo.KeyF6:    "\x1b[17~",
+	terminfo.KeyF7:    "\x1b[18~",
+	terminfo.KeyF8:    "\x1b[19~",
+	terminfo.KeyF9:    "\x1b[20~",
+	terminfo.KeyF10:   "\x1b[21~",
+	terminfo.KeyF11:   "\x1b[23~",
+	terminfo.KeyF12:   "\x1b[24~",
+}
+
+// extendedKeyCaps maps key names to the user-defined terminfo capabilities
+// ncurses describes them with, for keys that have no standard capability.
+var extendedKeyCaps = map[string]string{
+	"paste-start": "PS",
+	"paste-end":   "PE",
+}
+
+// xtermExtendedKeys are xterm's values for extendedKeyCaps.
+var xtermExtendedKeys = map[string]string{
+	"PS": "\x1b[200~",
+	"PE": "\x1b[201~",
+}
+
+// vtKeys are sequences other terminals send for the same keys, such as the
+// rxvt and linux console Home and End. Shells bind them whatever $TERM is.
+var vtKeys = map[string]string{
+	"\x1b[1~": "home",
+	"\x1b[4~": "end",
+	"\x1b[7~": "home",
+	"\x1b[8~": "end",
+}
+
+// modifiedKeyCaps maps key names to the prefix of the extended capabilities
+// ncurses defines for them with modifiers: kDC is Shift+Delete and kDC3 to
+// kDC7 add Alt, Shift+Alt, Ctrl, Ctrl+Shift and Ctrl+Alt, numbered like
+// xterm's modifier parameter.
+var modifiedKeyCaps = map[string]string{
+	"up":     "kUP",
+	"down":   "kDN",
+	"right":  "kRIT",
+	"left":   "kLFT",
+	"home":   "kHOM",
+	"end":    "kEND",
+	"insert": "kIC",
+	"delete": "kDC",
+	"prior":  "kPRV",
+	"next":   "kNXT",
+}
+
+var loadTerminfo = func() (*terminfo.Terminfo, error) {
+	return terminfo.LoadFromEnv()
+}
+
+// terminalKey returns the sequence the terminal in $TERM sends for a named
+// key such as "up", "home" or "f5", falling back to xterm's.
+func terminalKey(name string) (string, bool) {
+	if capability, ok := extendedKeyCaps[name]; ok {
+		if sequence, ok := terminfoExtendedString(capability); ok {
+			return sequence, true
+		}
+		return xtermExtendedKeys[capability], true
+	}
+
+	capability, ok := terminalKeyCaps[name]
+	if !ok {
+		return "", false
+	}
+
+	if ti, err := loadTerminfo(); err == nil {
+		if sequence := ti.Strings[capability]; len(sequence) > 0 {
+			return string(sequence), true
+		}
+	}
+
+	sequence, ok := xtermKeys[capability]
+	return sequence, ok
+}
+
+// terminfoExtendedString looks up a user-defined string capability of the
+// terminal in $TERM.
+func terminfoExtendedString(capability string) (string, bool) {
+	ti, err := loadTerminfo()
+	if err != nil {
+		return "", false
+	}
+	for i, name := range ti.ExtStringNames {
+		if string(name) == capability && len(ti.ExtStrings[i]) > 0 {
+			return string(ti.ExtStrings[i]), true
+		}
+	}
+	return "", false
+}
+
+// terminalKeyNames lists the names terminalKeyName may return, in order of
+// preference.
+var terminalKeyNames = []string{
+	"up", "down", "right", "left", "home", "end", "insert", "delete",
+	"prior", "next", "backtab",
+	"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11", "f12",
+	"paste-start", "paste-end",
+}
+
+// terminalKeyName is the inverse of terminalKey. Both the terminfo and the
+// xterm sequence are recognised, since shells often bind both.
+func terminalKeyName(keys string) (string, bool) {
+	normal := keys
+	if len(keys) == 3 && keys[1] == 'O' && keys[2] >= 'A' && keys[2] <= 'H' {
+		// Application cursor mode arrows, Home and End
+		normal = "\x1b[" + keys[2:]
+	}
+
+	for _, name := range terminalKeyNames {
+		if sequence, ok := terminalKey(name); ok && sequence == keys {
+			return name, true
+		}
+		if capability, ok := extendedKeyCaps[name]; ok {
+			if xtermExtendedKeys[capability] == keys {
+				return name, true
+			}
+		} else if xtermKeys[terminalKeyCaps[name]] == normal {
+			return name, true
+		}
+	}
+
+	name, ok := vtKeys[keys]
+	return name, ok
+}
+
+// terminfoModifiedKey returns the sequence $TERM's terminfo gives for a key
+// with the xterm modifier parameter code (2 for Shift up to 8), if any.
+func terminfoModifiedKey(name string, code int) (string, bool) {
+	prefix, ok := modifiedKeyCaps[name]
+	if !ok || code < 2 {
+		return "", false
+	}
+	if code > 2 {
+		prefix += strconv.Itoa(code)
+	}
+	return terminfoExtendedString(prefix)
+}
+
+// terminfoModifiedKeyName is the inverse of terminfoModifiedKey.
+func terminfoModifiedKeyName(keys string) (string, int, bool) {
+	for name := range modifiedKeyCaps {
+		for code := 2; code <= 8; code++ {
+			if sequence, ok := terminfoModifiedKey(name, code); ok && sequence == keys {
+				return name, code, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// xtermModifiedKey applies Ctrl and Shift to a CSI or SS3 key sequence using
+// xterm's modifier parameter, e.g. Ctrl+↑ becomes "\e[1;5A".
+func xtermModifiedKey(keys string, ctrl bool, shift bool) (string, bool) {
+	if len(keys) < 3 || keys[0] != 0x1b || (keys[1] != '[' && keys[1] != 'O') {
+		return "", false
+	}
+
+	modifier := 1
+	if shift {
+		modifier += 1
+	}
+	if ctrl {
+		modifier += 4
+	}
+
+	final := keys[len(keys)-1]
+	params := keys[2 : len(keys)-1]
+	if keys[1] == 'O' || params == "" {
+		params = "1"
+	}
+	if strings.Contains(params, ";") {
+		return "", false
+	}
+
+	return "\x1b[" + params + ";" + strconv.Itoa(modifier) + string(final), true
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/xo/terminfo"
+)
+
+func TestTerminalKeyFromTerminfo(t *testing.T) {
+	original := loadTerminfo
+	loadTerminfo = func() (*terminfo.Terminfo, error) {
+		return &terminfo.Terminfo{Strings: map[int][]byte{
+			terminfo.KeyUp: []byte("\x1bOA"),
+			terminfo.KeyF5: []byte("\x1b[15;1~"),
+		}}, nil
+	}
+	defer func() { loadTerminfo = original }()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"up", "\x1bOA"},
+		{"f5", "\x1b[15;1~"},
+		{"home", "\x1b[H"}, // missing from terminfo, falls back to xterm
+	}
+
+	for _, test := range tests {
+		result, ok := terminalKey(test.name)
+		if !ok || result != test.expected {
+			t.Errorf("terminalKey(%q) = %q, %v, want %q", test.name, result, ok, test.expected)
+		}
+	}
+
+	if _, ok := terminalKey("f99"); ok {
+		t.Error("terminalKey(f99) should not be found")
+	}
+	if name, ok := terminalKeyName("\x1b[A"); !ok || name != "up" {
+		t.Errorf("terminalKeyName(\\e[A) = %q, %v, want up", name, ok)
+	}
+}
+
+func TestTerminfoKeyNames(t *testing.T) {
+	original := loadTerminfo
+	loadTerminfo = func() (*terminfo.Terminfo, error) {
+		return &terminfo.Terminfo{
+			Strings: map[int][]byte{
+				terminfo.KeyHome: []byte("\x1b[1~"),
+			},
+			ExtStringNames: map[int][]byte{0: []byte("kDC5"), 1: []byte("PS")},
+			ExtStrings:     map[int][]byte{0: []byte("\x1b[3^"), 1: []byte("\x1b[200~")},
+		}, nil
+	}
+	defer func() { loadTerminfo = original }()
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"\x1b[3~", "Delete"},
+		{"\x1bOH", "Home"},
+		{"\x1b[1~", "Home"},
+		{"\x1b[8~", "End"},
+		{"\x1b[Z", "Shift+Tab"},
+		{"\x1b[3^", "Ctrl+Delete"},
+		{"\x1b[3;5~", "Ctrl+Delete"},
+		{"\x1b[200~", "PasteStart"},
+		{"\x1b[1;2P", "Shift+F1"},
+	}
+
+	for _, test := range tests {
+		if got := keySequenceFromBytes(test.keys).Display(); got != test.expected {
+			t.Errorf("keySequenceFromBytes(%q).Display() = %q, want %q", test.keys, got, test.expected)
+		}
+	}
+
+	if keys, err := keySequenceBytes("Ctrl+Delete"); err != nil || keys != "\x1b[3^" {
+		t.Errorf("keySequenceBytes(Ctrl+Delete) = %q, %v, want the kDC5 sequence", keys, err)
+	}
+	if got := normalizeKey("^[[3~"); got != "Delete" {
+		t.Errorf("normalizeKey(^[[3~) = %q, want Delete", got)
+	}
+}
+
+func TestResolveKeys(t *testing.T) {
+	withXtermKeys(t)
+
+	bindings := `bindkey -M emacs "^[[A" up-line-or-history
+bindkey -M emacs "^P" up-line-or-history
+`
+	tests := []struct {
+		shortcut Shortcut
+		expected string
+	}{
+		{Shortcut{Type: "widget", Target: "up-line-or-history"}, "\x10"},
+		{Shortcut{Type: "widget", Target: "atuin-search"}, ""},
+		{Shortcut{Type: "sequence", Target: "C-c"}, "\x03"},
+		{Shortcut{Type: "sequence", Target: "<f5>"}, "\x1b[15~"},
+		{Shortcut{Type: "command", Target: "git status"}, ""},
+	}
+
+	for _, test := range tests {
+		keys, err := ResolveKeys(test.shortcut, bindings, "emacs")
+		if err != nil || keys != test.expected {
+			t.Errorf("ResolveKeys(%+v) = %q, %v, want %q", test.shortcut, keys, err, test.expected)
+		}
+	}
+
+	if _, err := ResolveKeys(Shortcut{Type: "sequence", Target: "Hyper+X"}, "", "emacs"); err == nil {
+		t.Error("ResolveKeys() with an invalid sequence should return an error")
+	}
+}
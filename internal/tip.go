@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// tipRule makes the widgets in targets likelier tips while the command line
+// being edited matches.
+type tipRule struct {
+	matches func(buffer string) bool
+	targets []string // Widget names in zsh, readline and fish
+}
+
+var tipRules = []tipRule{
+	{
+		// Long or multi-line commands are easier to edit in $EDITOR
+		matches: func(buffer string) bool {
+			return len(buffer) > 60 || strings.Contains(buffer, "\n")
+		},
+		targets: []string{
+			"edit-command-line", "edit-and-execute-command", "edit_command_buffer",
+			"backward-kill-line", "unix-line-discard", "kill-whole-line", "push-line",
+		},
+	},
+	{
+		matches: func(buffer string) bool {
+			return len(strings.Fields(buffer)) >= 4
+		},
+		targets: []string{
+			"backward-word", "forward-word", "backward-kill-word", "kill-word",
+			"nextd-or-forward-word", "prevd-or-backward-word", "unix-word-rubout",
+			"beginning-of-line", "end-of-line",
+		},
+	},
+	{
+		matches: func(buffer string) bool {
+			return strings.TrimSpace(buffer) == ""
+		},
+		targets: []string{
+			"history-incremental-search-backward", "reverse-search-history", "history-pager",
+			"insert-last-word", "yank-last-arg", "history-token-search-backward",
+			"up-line-or-history", "previous-history", "up-or-search",
+		},
+	},
+	{
+		matches: func(buffer string) bool {
+			return strings.Contains(buffer, "/")
+		},
+		targets: []string{
+			"expand-or-complete", "complete", "menu-complete", "complete-and-search",
+			"backward-kill-path-component",
+		},
+	},
+}
+
+// tipRelevanceWeight multiplies the weight of tips a rule picks out.
+const tipRelevanceWeight = 20
+
+// pickTip chooses a shortcut to show as a tip, weighted towards ones seldom
+// or never picked in usage and ones that suit buffer, the command line being
+// edited. Only shortcuts with keys in keymap, or in no keymap, are tips.
+func pickTip(shortcuts []Shortcut, usage *UsageStore, buffer string, keymap string, rng *rand.Rand) (Shortcut, bool) {
+	var relevant []string
+	for _, rule := range tipRules {
+		if rule.matches(buffer) {
+			relevant = append(relevant, rule.targets...)
+		}
+	}
+
+	var candidates []Shortcut
+	var weights []float64
+	total := 0.0
+	for _, shortcut := range shortcuts {
+		if len(shortcut.Keys) == 0 || (shortcut.Keymap != "" && shortcut.Keymap != keymap) {
+			continue
+		}
+
+		weight := 1.0
+		if usage != nil {
+			weight /= float64(1 + usage.Entries[usageKey(shortcut)].Count)
+		}
+		if shortcut.Type == "widget" && contains(relevant, shortcut.Target) {
+			weight *= tipRelevanceWeight
+		}
+
+		candidates = append(candidates, shortcut)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if len(candidates) == 0 {
+		return Shortcut{}, false
+	}
+
+	pick := rng.Float64() * total
+	for i, weight := range weights {
+		pick -= weight
+		if pick < 0 {
+			return candidates[i], true
+		}
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// Tip picks a shortcut to suggest for buffer, see pickTip.
+func Tip(shortcuts []Shortcut, usage *UsageStore, buffer string, keymap string) (Shortcut, bool) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return pickTip(shortcuts, usage, buffer, keymap, rng)
+}
+
+// FormatTip renders a tip on one line with the theme's key and description
+// styles.
+func FormatTip(shortcut Shortcut, styles ThemeStyles, keyStyle string) string {
+	return styles.Help.Render("Tip: ") +
+		styles.Command.Render(shortcut.KeyLabel(keyStyle)) +
+		styles.Description.Render("  "+shortcut.Description)
+}
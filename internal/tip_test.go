@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestPickTip(t *testing.T) {
+	shortcuts := getZshBuiltinShortcuts()
+	for i := range shortcuts {
+		shortcuts[i].Keys, _ = ParseKeySequence(shortcuts[i].Display)
+	}
+	shortcuts = append(shortcuts, Shortcut{Display: "gs", Type: "command", Target: "git status"})
+
+	pickCounts := func(usage *UsageStore, buffer string) map[string]int {
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[string]int)
+		for i := 0; i < 2000; i++ {
+			tip, ok := pickTip(shortcuts, usage, buffer, "emacs", rng)
+			if !ok {
+				t.Fatal("pickTip() found no tip")
+			}
+			counts[tip.Display]++
+		}
+		return counts
+	}
+
+	counts := pickCounts(nil, "")
+	if counts["gs"] != 0 {
+		t.Error("Shortcuts without keys should not be tips")
+	}
+
+	long := "rsync -avz --progress --exclude node_modules ./project/ backup-host:/srv/backups/project/"
+	if plain, relevant := pickCounts(nil, "ls")["Ctrl+X Ctrl+E"], pickCounts(nil, long)["Ctrl+X Ctrl+E"]; relevant <= 3*plain {
+		t.Errorf("A long buffer should favour Ctrl+X Ctrl+E: picked %d times, %d for a short one", relevant, plain)
+	}
+
+	usage := &UsageStore{Entries: map[string]UsageEntry{
+		mergeKey("emacs", "Ctrl+A"): {Count: 50},
+	}}
+	if used, unused := pickCounts(usage, "ls")["Ctrl+A"], pickCounts(nil, "ls")["Ctrl+A"]; used*5 >= unused {
+		t.Errorf("A much used shortcut should be a rare tip: picked %d times, %d when unused", used, unused)
+	}
+
+	if _, ok := pickTip(shortcuts, nil, "", "vicmd", rand.New(rand.NewSource(1))); ok {
+		t.Error("Emacs shortcuts should not be tips in vicmd")
+	}
+	bashKeys, _ := ParseKeySequence("Ctrl+R")
+	bash := []Shortcut{{Display: "Ctrl+R", Keys: bashKeys, Type: "widget", Target: "reverse-search-history"}}
+	if _, ok := pickTip(bash, nil, "", "vicmd", rand.New(rand.NewSource(1))); !ok {
+		t.Error("Keymap-independent shortcuts should be tips in any keymap")
+	}
+	if _, ok := pickTip(nil, nil, "", "emacs", rand.New(rand.NewSource(1))); ok {
+		t.Error("pickTip() with no shortcuts should find no tip")
+	}
+}
+
+func TestFormatTip(t *testing.T) {
+	keys, _ := ParseKeySequence("Ctrl+X Ctrl+E")
+	shortcut := Shortcut{Display: "Ctrl+X Ctrl+E", Keys: keys, Description: "Edit command in editor"}
+
+	tip := FormatTip(shortcut, CreateThemeStyles(GetDefaultTheme()), "emacs")
+	if !strings.Contains(tip, "C-x C-e") || !strings.Contains(tip, "Edit command in editor") {
+		t.Errorf("FormatTip() = %q, want the key in emacs notation and the description", tip)
+	}
+}
@@ -3,12 +3,13 @@ package internal
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
-	"github.com/sahilm/fuzzy"
 )
 
 type model struct {
@@ -24,12 +25,27 @@ type model struct {
 	scrollOffset int
 	maxVisible   int
 	styles       ThemeStyles
+	keymap       string          // Active keymap, empty to show every keymap
+	keymaps      []string        // Keymaps present in shortcuts, in display order
+	keyStyle     string          // Notation for the key column, one of KeyStyles
+	collapsed    map[string]bool // Categories whose sections are folded
+	usage        *UsageStore     // Past selections to rank by, nil to keep the given order
+	now          time.Time       // When the picker opened, for scoring usage
+	sheet        bool            // Showing every match at once as a cheat sheet
+	sheetOffset  int             // First sheet line shown
+}
+
+// listRow is one line of the list: a category header or a shortcut.
+type listRow struct {
+	category string // Category of a header row, empty for shortcut rows
+	count    int    // Shortcuts in the header's section
+	index    int    // Index into filtered for shortcut rows
 }
 
 type tickMsg struct{}
 
 func InitialModel(shortcuts []Shortcut, styles ThemeStyles) model {
-	return model{
+	m := model{
 		shortcuts:    shortcuts,
 		filtered:     shortcuts,
 		cursor:       0,
@@ -37,7 +53,68 @@ func InitialModel(shortcuts []Shortcut, styles ThemeStyles) model {
 		scrollOffset: 0,
 		maxVisible:   10,
 		styles:       styles,
+		keymaps:      availableKeymaps(shortcuts),
+		collapsed:    make(map[string]bool),
+		now:          time.Now(),
+	}
+	return m.withKeymap("")
+}
+
+// withKeymap makes keymap the active keymap, falling back to emacs (or to
+// showing everything) when the shortcuts have no bindings for it.
+func (m model) withKeymap(keymap string) model {
+	m.keymap = resolveKeymap(m.keymaps, keymap)
+	m.filtered = m.filterShortcuts()
+	m.cursor = 0
+	m.scrollOffset = 0
+	return m
+}
+
+// resolveKeymap returns keymap when it is one of keymaps, else emacs when
+// it is, else the empty keymap that stands for all of them.
+func resolveKeymap(keymaps []string, keymap string) string {
+	resolved := ""
+	for _, available := range keymaps {
+		if available == keymap {
+			resolved = keymap
+		} else if available == "emacs" && resolved == "" {
+			resolved = "emacs"
+		}
 	}
+	return resolved
+}
+
+// ActiveKeymap returns the keymap the picker would start in for keymap:
+// keymap itself when shortcuts have bindings in it, else emacs, else the
+// empty keymap that shows them all.
+func ActiveKeymap(shortcuts []Shortcut, keymap string) string {
+	return resolveKeymap(availableKeymaps(shortcuts), keymap)
+}
+
+var keymapOrder = []string{"emacs", "viins", "vicmd", "visual"}
+
+func availableKeymaps(shortcuts []Shortcut) []string {
+	present := make(map[string]bool)
+	for _, shortcut := range shortcuts {
+		if shortcut.Keymap != "" {
+			present[shortcut.Keymap] = true
+		}
+	}
+
+	var keymaps []string
+	for _, keymap := range keymapOrder {
+		if present[keymap] {
+			keymaps = append(keymaps, keymap)
+			delete(present, keymap)
+		}
+	}
+	var others []string
+	for keymap := range present {
+		others = append(others, keymap)
+	}
+	sort.Strings(others)
+
+	return append(keymaps, others...)
 }
 
 func (m model) Shortcuts() []Shortcut {
@@ -56,25 +133,73 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.sheet {
+			// The sheet scrolls instead of moving a cursor and has nothing
+			// to select; typing still narrows it
+			switch msg.String() {
+			case "ctrl+o", "esc":
+				m.sheet = false
+				return m, nil
+			case "up":
+				m.sheetOffset = m.clampSheetOffset(m.sheetOffset - 1)
+				return m, nil
+			case "down":
+				m.sheetOffset = m.clampSheetOffset(m.sheetOffset + 1)
+				return m, nil
+			case "enter", "tab", "left", "right":
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
-			if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
-				m.selected = &m.filtered[m.cursor]
-				m.selectedKey = "enter"
+		case "enter", "tab":
+			rows := m.rows()
+			if m.cursor < len(rows) {
+				row := rows[m.cursor]
+				if row.category != "" {
+					m.collapsed[row.category] = !m.collapsed[row.category]
+					return m, nil
+				}
+				m.selected = &m.filtered[row.index]
+				m.selectedKey = msg.String()
 				m.quitting = true
 				return m, tea.Quit
 			}
 
-		case "tab":
-			if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
-				m.selected = &m.filtered[m.cursor]
-				m.selectedKey = "tab"
-				m.quitting = true
-				return m, tea.Quit
+		case "left", "right":
+			// Fold or unfold the section under the cursor
+			rows := m.rows()
+			if m.cursor < len(rows) {
+				category := rows[m.cursor].category
+				if category == "" {
+					category = m.sectionOf(rows, m.cursor)
+				}
+				if category != "" {
+					m.collapsed[category] = msg.String() == "left"
+					m.cursor = m.headerRow(category)
+					if m.cursor < m.scrollOffset {
+						m.scrollOffset = m.cursor
+					}
+				}
+			}
+
+		case "ctrl+o":
+			m.sheet = true
+			m.sheetOffset = 0
+
+		case "ctrl+k":
+			if len(m.keymaps) > 1 {
+				next := m.keymaps[0]
+				for i, keymap := range m.keymaps {
+					if keymap == m.keymap && i+1 < len(m.keymaps) {
+						next = m.keymaps[i+1]
+					}
+				}
+				return m.withKeymap(next), nil
 			}
 
 		case "up":
@@ -87,7 +212,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "down":
-			if m.cursor < len(m.filtered)-1 {
+			if m.cursor < len(m.rows())-1 {
 				m.cursor++
 				if m.cursor-m.scrollOffset > 9 {
 					m.scrollOffset++
@@ -113,10 +238,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filtered = m.filterShortcuts()
 				m.cursor = 0
 				m.scrollOffset = 0
+				m.sheetOffset = 0
 			}
 		}
 
 	case tea.MouseMsg:
+		if m.sheet {
+			if msg.Type == tea.MouseWheelUp {
+				m.sheetOffset = m.clampSheetOffset(m.sheetOffset - 1)
+			}
+			if msg.Type == tea.MouseWheelDown {
+				m.sheetOffset = m.clampSheetOffset(m.sheetOffset + 1)
+			}
+			return m, nil
+		}
 		if msg.Type == tea.MouseLeft {
 			displayLine := msg.Y - (m.height - 14)
 			item := displayLine - 2
@@ -135,7 +270,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		if msg.Type == tea.MouseWheelDown {
-			if m.cursor < len(m.filtered)-1 {
+			if m.cursor < len(m.rows())-1 {
 				m.cursor++
 				if m.cursor-m.scrollOffset > m.maxVisible-1 {
 					m.scrollOffset++
@@ -147,26 +282,193 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m model) filterShortcuts() []Shortcut {
-	if m.query == "" {
+// keymapShortcuts returns the shortcuts that apply in the active keymap.
+func (m model) keymapShortcuts() []Shortcut {
+	if m.keymap == "" {
 		return m.shortcuts
 	}
 
-	targets := make([]string, len(m.shortcuts))
-	for i, shortcut := range m.shortcuts {
-		targets[i] = shortcut.Display + " " + shortcut.Description
+	shortcuts := make([]Shortcut, 0, len(m.shortcuts))
+	for _, shortcut := range m.shortcuts {
+		if shortcut.Keymap == "" || shortcut.Keymap == m.keymap {
+			shortcuts = append(shortcuts, shortcut)
+		}
+	}
+	return shortcuts
+}
+
+// filterShortcuts returns the shortcuts matching the query, best fuzzy
+// matches first, boosted by how often and how recently each was picked.
+// See parseQuery for the query syntax.
+func (m model) filterShortcuts() []Shortcut {
+	shortcuts := m.keymapShortcuts()
+
+	query := parseQuery(m.query)
+	if len(query) == 0 && m.usage == nil {
+		return shortcuts
 	}
 
-	matches := fuzzy.Find(m.query, targets)
+	var filtered []Shortcut
+	var scores []int
+	for _, shortcut := range shortcuts {
+		fields := append([]string{shortcut.KeyLabel(m.keyStyle), shortcut.Description}, keyNotations(shortcut)...)
+		if score, ok := query.match(shortcut, fields); ok {
+			filtered = append(filtered, shortcut)
+			scores = append(scores, score+frecencyBoost(m.usage.Frecency(shortcut, m.now)))
+		}
+	}
 
-	filtered := make([]Shortcut, len(matches))
-	for i, match := range matches {
-		filtered[i] = m.shortcuts[match.Index]
+	if query.fuzzy() || m.usage != nil {
+		order := make([]int, len(filtered))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return scores[order[i]] > scores[order[j]]
+		})
+		sorted := make([]Shortcut, len(filtered))
+		for i, index := range order {
+			sorted[i] = filtered[index]
+		}
+		filtered = sorted
 	}
 
 	return filtered
 }
 
+// grouped reports whether the list is shown in category sections: when
+// there is no query and the shortcuts have categories.
+func (m model) grouped() bool {
+	if m.query != "" {
+		return false
+	}
+	for _, shortcut := range m.filtered {
+		if shortcut.Category != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// frequentSection is the section heading the grouped list with the
+// shortcuts picked most, ahead of the categories.
+const frequentSection = "frequently used"
+
+// frequentSectionSize is how many shortcuts the frequent section holds.
+const frequentSectionSize = 5
+
+// frequent returns the indexes into filtered of the shortcuts with the most
+// frecency, best first. The filtered list is already ranked by it.
+func (m model) frequent() []int {
+	var indexes []int
+	for i, shortcut := range m.filtered {
+		if len(indexes) == frequentSectionSize {
+			break
+		}
+		if m.usage.Frecency(shortcut, m.now) > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// rows lays out the filtered shortcuts, under a header per category when
+// the list is grouped, led by the frequent section when there is usage.
+// Folded sections only show their header.
+func (m model) rows() []listRow {
+	if !m.grouped() {
+		rows := make([]listRow, len(m.filtered))
+		for i := range m.filtered {
+			rows[i] = listRow{index: i}
+		}
+		return rows
+	}
+
+	var rows []listRow
+	if frequent := m.frequent(); len(frequent) > 0 {
+		rows = append(rows, listRow{category: frequentSection, count: len(frequent)})
+		if !m.collapsed[frequentSection] {
+			for _, index := range frequent {
+				rows = append(rows, listRow{index: index})
+			}
+		}
+	}
+	for _, category := range orderedCategories(m.filtered) {
+		header := len(rows)
+		rows = append(rows, listRow{category: category})
+		for i, shortcut := range m.filtered {
+			if categoryOf(shortcut) != category {
+				continue
+			}
+			rows[header].count++
+			if !m.collapsed[category] {
+				rows = append(rows, listRow{index: i})
+			}
+		}
+	}
+	return rows
+}
+
+// sectionOf returns the category of the section containing rows[i].
+func (m model) sectionOf(rows []listRow, i int) string {
+	for ; i >= 0; i-- {
+		if rows[i].category != "" {
+			return rows[i].category
+		}
+	}
+	return ""
+}
+
+// headerRow returns the row of a category's header.
+func (m model) headerRow(category string) int {
+	for i, row := range m.rows() {
+		if row.category == category {
+			return i
+		}
+	}
+	return 0
+}
+
+// sheetLines lays out the filtered shortcuts as a cheat sheet indented
+// under the status line.
+func (m model) sheetLines() []string {
+	width := m.width
+	if width == 0 {
+		width = sheetDefaultWidth
+	}
+	return sheetLines(m.filtered, m.styles, m.keyStyle, width-2)
+}
+
+// sheetHeight returns how many sheet lines fit between the status and help
+// lines.
+func (m model) sheetHeight() int {
+	if m.height == 0 {
+		return m.maxVisible
+	}
+	return max(m.height-4, 5)
+}
+
+// clampSheetOffset keeps a sheet scroll offset within the sheet.
+func (m model) clampSheetOffset(offset int) int {
+	return max(0, min(offset, len(m.sheetLines())-m.sheetHeight()))
+}
+
+// keyNotations lists a shortcut's keys in every key style, so the search
+// matches "C-x C-e" or "^X^E" whichever style the key column uses.
+func keyNotations(shortcut Shortcut) []string {
+	if shortcut.Keys == nil {
+		return nil
+	}
+
+	notations := make([]string, len(KeyStyles))
+	for i, style := range KeyStyles {
+		notations[i] = shortcut.Keys.Format(style)
+	}
+	return notations
+}
+
+// highlightMatches renders text with the characters matched by each term of
+// the query in the match colour.
 func (m model) highlightMatches(text string, query string, baseStyle lipgloss.Style, isSelected bool, styles ThemeStyles) string {
 	if query == "" {
 		if isSelected {
@@ -175,40 +477,20 @@ func (m model) highlightMatches(text string, query string, baseStyle lipgloss.St
 		return baseStyle.Render(text)
 	}
 
-	highlighted := ""
-	unhighlighted := ""
-	queryLower := strings.ToLower(query)
-	queryIndex := 0
-	maxMatchLength := 0
-	currentMatchLength := 0
+	positions := parseQuery(query).highlights(text)
 
-	for _, char := range text {
+	var b strings.Builder
+	for i, char := range []rune(text) {
 		charStyle := baseStyle.Copy()
 		if isSelected {
 			charStyle = charStyle.Background(m.styles.SelectedLine.GetBackground())
 		}
-
-		if queryIndex < len(queryLower) && strings.ToLower(string(char)) == string(queryLower[queryIndex]) {
-			matchChar := charStyle.Foreground(m.styles.Match.GetForeground()).Render(string(char))
-			highlighted += matchChar
-			queryIndex++
-			currentMatchLength++
-			if currentMatchLength > maxMatchLength {
-				maxMatchLength = currentMatchLength
-			}
-		} else {
-			currentMatchLength = 0
-			highlighted += charStyle.Render(string(char))
+		if positions[i] {
+			charStyle = charStyle.Foreground(m.styles.Match.GetForeground())
 		}
-		unhighlighted += charStyle.Render(string(char))
-	}
-
-	matchDiff := len(query) - maxMatchLength
-	if matchDiff < 2 {
-		return highlighted
+		b.WriteString(charStyle.Render(string(char)))
 	}
-
-	return unhighlighted
+	return b.String()
 }
 
 func (m model) View() string {
@@ -223,9 +505,15 @@ func (m model) View() string {
 	a.WriteString(m.styles.Query.Render(m.query))
 	a.WriteString("\n")
 
-	totalCount := len(m.shortcuts)
+	totalCount := len(m.keymapShortcuts())
 	filteredCount := len(m.filtered)
 	status := fmt.Sprintf("  %d/%d ", filteredCount, totalCount)
+	if m.keymap != "" {
+		status += "[" + m.keymap + "] "
+	}
+	if filters := parseQuery(m.query).filters(); len(filters) > 0 {
+		status += "filter: " + strings.Join(filters, " ") + " "
+	}
 	b.WriteString(m.styles.Status.Render(status))
 
 	separatorLength := m.width - len(status) - 2
@@ -241,24 +529,67 @@ func (m model) View() string {
 		m.maxVisible = 5
 	}
 
+	if m.sheet {
+		lines := m.sheetLines()
+		start := m.clampSheetOffset(m.sheetOffset)
+		end := min(start+m.sheetHeight(), len(lines))
+		for _, line := range lines[start:end] {
+			b.WriteString("  ")
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+		help := "Ctrl+O/Esc: back to list • Ctrl+C: quit"
+		if len(lines) > end-start {
+			help = "↑/↓: scroll • " + help
+		}
+		if len(m.keymaps) > 1 {
+			help += " • Ctrl+K: keymap"
+		}
+		b.WriteString(m.styles.Help.Render(help))
+
+		a.WriteString(m.styles.AppBackground.Render(b.String()))
+		return a.String()
+	}
+
 	start := m.scrollOffset
 
+	rows := m.rows()
 	end := start + m.maxVisible
-	if end > len(m.filtered) {
-		end = len(m.filtered)
+	if end > len(rows) {
+		end = len(rows)
 	}
 
 	for i := start; i < end; i++ {
-		shortcut := m.filtered[i]
+		row := rows[i]
+		if row.category != "" {
+			marker := "▾"
+			if m.collapsed[row.category] {
+				marker = "▸"
+			}
+			header := fmt.Sprintf("%s %s (%d)", marker, categoryTitle(row.category), row.count)
+			if i == m.cursor {
+				b.WriteString(m.styles.SelectedBar.Render("▌"))
+			} else {
+				b.WriteString(m.styles.UnselectedBar.Render("█"))
+			}
+			b.WriteString(m.styles.AppBackground.Render(" "))
+			b.WriteString(m.styles.Title.Render(header))
+			b.WriteString("\n")
+			continue
+		}
+
+		shortcut := m.filtered[row.index]
 		commandWidth := 22
 		indicatorWidth := 3
 		if m.width > 80 {
 			commandWidth = 30
 		}
 
-		command := shortcut.Display
-		if len(command) > commandWidth {
-			command = command[:commandWidth-3] + "..."
+		command := shortcut.KeyLabel(m.keyStyle)
+		if runes := []rune(command); len(runes) > commandWidth {
+			command = string(runes[:commandWidth-3]) + "..."
 		} else {
 			command = fmt.Sprintf("%-*s", commandWidth, command)
 		}
@@ -296,7 +627,15 @@ func (m model) View() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(m.styles.Help.Render("↑/↓: navigate • Enter: execute • Tab: populate • Esc: quit"))
+	help := "↑/↓: navigate • Enter: execute • Tab: populate • Esc: quit"
+	if m.grouped() {
+		help += " • ←/→: fold"
+	}
+	if len(m.keymaps) > 1 {
+		help += " • Ctrl+K: keymap"
+	}
+	help += " • Ctrl+O: sheet"
+	b.WriteString(m.styles.Help.Render(help))
 
 	a.WriteString(m.styles.AppBackground.Render(b.String()))
 	return a.String()
@@ -304,11 +643,17 @@ func (m model) View() string {
 	// return m.styles.AppBackground.Render(content)
 }
 
-func ShowUI(shortcuts []Shortcut, styles ThemeStyles) (*Shortcut, string, error) {
+// ShowUI runs the picker, starting in the given keymap when it has bindings
+// and rendering keys in keyStyle. Shortcuts picked often in usage are ranked
+// first; a nil usage keeps the given order.
+func ShowUI(shortcuts []Shortcut, styles ThemeStyles, keymap string, keyStyle string, usage *UsageStore) (*Shortcut, string, error) {
 	// Force true color support
 	lipgloss.SetColorProfile(termenv.TrueColor)
 	
 	m := InitialModel(shortcuts, styles)
+	m.keyStyle = keyStyle
+	m.usage = usage
+	m = m.withKeymap(keymap)
 
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
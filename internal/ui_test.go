@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -104,6 +106,72 @@ func TestFilterShortcuts(t *testing.T) {
 	}
 }
 
+func TestFilterShortcutsExtendedQuery(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+W", Description: "Kill word backward", Type: "widget", Target: "backward-kill-word"},
+		{Display: "Alt+D", Description: "Kill word forward", Type: "widget", Target: "kill-word"},
+		{Display: "Ctrl+Y", Description: "Yank", Type: "widget", Target: "yank"},
+	}
+
+	model := createTestModel(shortcuts)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"word kill", []string{"Ctrl+W", "Alt+D"}},
+		{"kill !backward", []string{"Alt+D"}},
+		{"^ctrl", []string{"Ctrl+W", "Ctrl+Y"}},
+		{"yank | forward$", []string{"Alt+D", "Ctrl+Y"}},
+		{"type:widget kill !target:backward", []string{"Alt+D"}},
+	}
+
+	for _, test := range tests {
+		model.query = test.query
+		var got []string
+		for _, shortcut := range model.filterShortcuts() {
+			got = append(got, shortcut.Display)
+		}
+		sort.Strings(got)
+		sort.Strings(test.expected)
+		if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("Query %q: got %v, want %v", test.query, got, test.expected)
+		}
+	}
+
+	model.width = 80
+	model.query = "type:widget !custom:yes kill"
+	model.filtered = model.filterShortcuts()
+	if view := model.View(); !strings.Contains(view, "filter: type:widget !custom:yes") {
+		t.Errorf("View should show the active filters:\n%s", view)
+	}
+}
+
+func TestFilterShortcutsAnyKeyNotation(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+X Ctrl+E", Keys: KeySequence{{Key: "x", Modifiers: ModCtrl}, {Key: "e", Modifiers: ModCtrl}}, Description: "Edit command line", Type: "widget", Target: "edit-command-line"},
+		{Display: "Alt+F", Keys: KeySequence{{Key: "f", Modifiers: ModAlt}}, Description: "Forward word", Type: "widget", Target: "forward-word"},
+	}
+
+	model := createTestModel(shortcuts)
+	model.keyStyle = "symbols"
+
+	for _, query := range []string{"C-x C-e", "^X^E", "Ctrl+X", "⌃X"} {
+		model.query = query
+		filtered := model.filterShortcuts()
+		if len(filtered) == 0 || filtered[0].Target != "edit-command-line" {
+			t.Errorf("Query %q should match Ctrl+X Ctrl+E first, got %+v", query, filtered)
+		}
+	}
+
+	model.query = ""
+	model.filtered = model.filterShortcuts()
+	view := model.View()
+	if !strings.Contains(view, "⌃X ⌃E") || !strings.Contains(view, "⌥F") {
+		t.Errorf("View() with symbols key style should render ⌃X ⌃E and ⌥F:\n%s", view)
+	}
+}
+
 func TestHighlightMatches(t *testing.T) {
 	shortcuts := []Shortcut{
 		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line"},
@@ -200,3 +268,213 @@ func TestModelUpdate(t *testing.T) {
 		t.Error("Escape key should return a command")
 	}
 }
+
+func TestModelKeymaps(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs"},
+		{Display: "0", Description: "Beginning of line", Type: "widget", Target: "vi-digit-or-beginning-of-line", Keymap: "vicmd"},
+		{Display: "Esc", Description: "Command mode", Type: "widget", Target: "vi-cmd-mode", Keymap: "viins"},
+		{Display: "gs", Description: "git status", Type: "command", Target: "git status"},
+	}
+
+	m := createTestModel(shortcuts)
+	if m.keymap != "emacs" {
+		t.Errorf("InitialModel keymap: got %q, want %q", m.keymap, "emacs")
+	}
+	if len(m.filtered) != 2 {
+		t.Errorf("emacs keymap should show 2 shortcuts, got %d", len(m.filtered))
+	}
+
+	m = m.withKeymap("vicmd")
+	if m.keymap != "vicmd" {
+		t.Errorf("withKeymap(vicmd): got %q", m.keymap)
+	}
+	for _, shortcut := range m.filtered {
+		if shortcut.Keymap != "" && shortcut.Keymap != "vicmd" {
+			t.Errorf("vicmd keymap should not show %q from %s", shortcut.Display, shortcut.Keymap)
+		}
+	}
+
+	m = m.withKeymap("nonexistent")
+	if m.keymap != "emacs" {
+		t.Errorf("Unknown keymap should fall back to emacs, got %q", m.keymap)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	m = updated.(model)
+	if m.keymap != "viins" {
+		t.Errorf("Ctrl+K should switch from emacs to viins, got %q", m.keymap)
+	}
+
+	m.width = 80
+	if !strings.Contains(m.View(), "[viins]") {
+		t.Error("View should show the active keymap")
+	}
+}
+
+func TestModelCategorySections(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Category: "movement"},
+		{Display: "Ctrl+K", Description: "Kill line", Type: "widget", Target: "kill-line", Category: "editing"},
+		{Display: "Ctrl+E", Description: "End of line", Type: "widget", Target: "end-of-line", Category: "movement"},
+	}
+
+	m := createTestModel(shortcuts)
+	m.width = 80
+
+	rows := m.rows()
+	if len(rows) != 5 {
+		t.Fatalf("rows() with two categories: got %d rows, want 5", len(rows))
+	}
+	if rows[0].category != "movement" || rows[0].count != 2 || rows[3].category != "editing" {
+		t.Errorf("rows() should put movement before editing: %+v", rows)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Movement (2)") || !strings.Contains(view, "Editing (1)") {
+		t.Errorf("View should show section headers:\n%s", view)
+	}
+
+	// Enter on a header folds its section instead of selecting
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd != nil || m.selected != nil {
+		t.Error("Enter on a header should not select a shortcut")
+	}
+	if len(m.rows()) != 3 {
+		t.Errorf("Folded movement section: got %d rows, want 3", len(m.rows()))
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m = updated.(model)
+	if len(m.rows()) != 5 {
+		t.Errorf("Unfolded movement section: got %d rows, want 5", len(m.rows()))
+	}
+
+	m.query = "kill"
+	m.filtered = m.filterShortcuts()
+	if m.grouped() {
+		t.Error("List should not be grouped while searching")
+	}
+	if strings.Contains(m.View(), "Editing (") {
+		t.Error("View should not show section headers while searching")
+	}
+}
+
+func TestFilterShortcutsFrecency(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Alt+D", Description: "Kill word forward", Type: "widget", Target: "kill-word"},
+		{Display: "Ctrl+K", Description: "Kill line", Type: "widget", Target: "kill-line"},
+		{Display: "Ctrl+W", Description: "Kill word backward", Type: "widget", Target: "backward-kill-word"},
+	}
+
+	m := createTestModel(shortcuts)
+	m.usage = &UsageStore{Entries: map[string]UsageEntry{
+		mergeKey("", "Ctrl+W"): {Count: 5, LastUsed: m.now},
+	}}
+
+	m.query = ""
+	if filtered := m.filterShortcuts(); filtered[0].Display != "Ctrl+W" {
+		t.Errorf("Empty query should list the most used shortcut first, got %s", filtered[0].Display)
+	}
+	m.query = "kill"
+	if filtered := m.filterShortcuts(); filtered[0].Display != "Ctrl+W" {
+		t.Errorf("Query 'kill' should rank the most used match first, got %s", filtered[0].Display)
+	}
+
+	m.usage = nil
+	m.query = ""
+	if filtered := m.filterShortcuts(); filtered[0].Display != "Alt+D" {
+		t.Errorf("Without usage the given order should be kept, got %s first", filtered[0].Display)
+	}
+}
+
+func TestModelFrequentSection(t *testing.T) {
+	shortcuts := []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Category: "movement"},
+		{Display: "Ctrl+E", Description: "End of line", Type: "widget", Target: "end-of-line", Category: "movement"},
+		{Display: "Ctrl+W", Description: "Kill word backward", Type: "widget", Target: "backward-kill-word", Category: "editing"},
+	}
+
+	m := createTestModel(shortcuts)
+	m.width = 80
+	m.usage = &UsageStore{Entries: map[string]UsageEntry{
+		mergeKey("", "Ctrl+W"): {Count: 5, LastUsed: m.now},
+	}}
+	m = m.withKeymap("")
+
+	rows := m.rows()
+	if len(rows) != 7 || rows[0].category != frequentSection || rows[0].count != 1 {
+		t.Fatalf("rows() with usage should lead with the frequent section: %+v", rows)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Frequently used (1)") {
+		t.Errorf("View should show the frequent section header:\n%s", view)
+	}
+	if strings.Index(view, "Kill word backward") > strings.Index(view, "Movement (2)") {
+		t.Errorf("The most used shortcut should come before the categories:\n%s", view)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if selected := updated.(model).selected; selected == nil || selected.Display != "Ctrl+W" {
+		t.Errorf("Enter on the first frequent row selected %+v, want Ctrl+W", selected)
+	}
+
+	m.usage = nil
+	m = m.withKeymap("")
+	if rows := m.rows(); rows[0].category != "movement" {
+		t.Errorf("Without usage there should be no frequent section: %+v", rows)
+	}
+}
+
+func TestModelSheet(t *testing.T) {
+	var shortcuts []Shortcut
+	for i := 0; i < 30; i++ {
+		shortcuts = append(shortcuts, Shortcut{Display: fmt.Sprintf("Ctrl+X %d", i), Description: fmt.Sprintf("Widget %d", i), Type: "widget"})
+	}
+	shortcuts = append(shortcuts, Shortcut{Display: "gs", Description: "Git status", Type: "command"})
+
+	m := createTestModel(shortcuts)
+	m.width = 40
+	m.height = 12
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = updated.(model)
+	if !m.sheet {
+		t.Fatal("Ctrl+O should open the sheet")
+	}
+	view := m.View()
+	if !strings.Contains(view, "Widgets (30)") || !strings.Contains(view, "↑/↓: scroll") {
+		t.Errorf("View should show the sheet with a scroll hint:\n%s", view)
+	}
+
+	// Down scrolls the sheet rather than moving the cursor, and stops at the end
+	for i := 0; i < 100; i++ {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = updated.(model)
+	}
+	if m.cursor != 0 || m.sheetOffset != len(m.sheetLines())-m.sheetHeight() {
+		t.Errorf("Scrolled to the end: cursor %d, offset %d of %d lines", m.cursor, m.sheetOffset, len(m.sheetLines()))
+	}
+	if !strings.Contains(m.View(), "Commands (1)") {
+		t.Error("The end of the sheet should show the commands group")
+	}
+
+	// Enter has nothing to select; typing narrows the sheet
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd != nil || m.selected != nil {
+		t.Error("Enter in the sheet should not select a shortcut")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git")})
+	m = updated.(model)
+	if view := m.View(); !strings.Contains(view, "Git status") || strings.Contains(view, "Widgets") {
+		t.Errorf("The query should narrow the sheet:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.sheet || m.quitting {
+		t.Error("Esc in the sheet should go back to the list")
+	}
+}
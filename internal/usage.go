@@ -0,0 +1,234 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UsageEntry is how often, and how recently, a shortcut was picked.
+type UsageEntry struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// UsageEvent is one selection in the usage log.
+type UsageEvent struct {
+	Key    string    `json:"key"`
+	Keymap string    `json:"keymap,omitempty"`
+	Type   string    `json:"type"`
+	Target string    `json:"target"`
+	Time   time.Time `json:"time"`
+}
+
+// UsageStore records the shortcuts picked in the picker, keyed by keymap and
+// key, so frequently and recently used ones can be ranked first.
+type UsageStore struct {
+	Entries map[string]UsageEntry `json:"entries"`
+}
+
+// UsagePath returns the location of the usage store.
+func UsagePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "shortcutter", "usage.json"), nil
+}
+
+// UsageLogPath returns the location of the usage log, which holds one JSON
+// UsageEvent per line.
+func UsageLogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "shortcutter", "history.jsonl"), nil
+}
+
+// LoadUsageLog reads every selection in the usage log, oldest first. Lines
+// that can't be parsed, such as a write cut short, are skipped.
+func LoadUsageLog() ([]UsageEvent, error) {
+	path, err := UsageLogPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []UsageEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event UsageEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// appendUsageEvent adds event to the end of the usage log.
+func appendUsageEvent(event UsageEvent) error {
+	path, err := UsageLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadUsage reads the usage store, which is empty until a first selection.
+func LoadUsage() (*UsageStore, error) {
+	store := &UsageStore{Entries: make(map[string]UsageEntry)}
+
+	path, err := UsagePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Entries == nil {
+		store.Entries = make(map[string]UsageEntry)
+	}
+	return store, nil
+}
+
+// Save writes the store.
+func (s *UsageStore) Save() error {
+	path, err := UsagePath()
+	if err != nil {
+		return err
+	}
+	return writeDataFile(path, s)
+}
+
+// writeDataFile writes value as JSON to path, replacing the file in one step
+// so concurrent shells never read a partial write.
+func writeDataFile(path string, value interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Record counts a selection of shortcut at now.
+func (s *UsageStore) Record(shortcut Shortcut, now time.Time) {
+	key := usageKey(shortcut)
+	entry := s.Entries[key]
+	entry.Count++
+	entry.LastUsed = now
+	s.Entries[key] = entry
+}
+
+// Frecency scores a shortcut by its use count, weighted by how recently it
+// was last used. Unused shortcuts, and a nil store, score 0.
+func (s *UsageStore) Frecency(shortcut Shortcut, now time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+	entry, ok := s.Entries[usageKey(shortcut)]
+	if !ok {
+		return 0
+	}
+
+	age := now.Sub(entry.LastUsed)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	}
+	return float64(entry.Count) * weight
+}
+
+// frecencyBoost turns a frecency score into points added to the fuzzy match
+// score. The log keeps a habitual shortcut from outranking a far better match.
+func frecencyBoost(frecency float64) int {
+	return int(math.Round(10 * math.Log2(1+frecency)))
+}
+
+// usageKey identifies a shortcut in the usage store.
+func usageKey(shortcut Shortcut) string {
+	return mergeKey(shortcut.Keymap, shortcut.Display)
+}
+
+// RecordUsage adds a selection to the usage store when ui ranks by
+// frecency, and to the usage log when it keeps stats.
+func RecordUsage(shortcut Shortcut, ui UIConfig) error {
+	now := time.Now()
+	if ui.StatsEnabled() {
+		err := appendUsageEvent(UsageEvent{
+			Key:    shortcut.Display,
+			Keymap: shortcut.Keymap,
+			Type:   shortcut.Type,
+			Target: shortcut.Target,
+			Time:   now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if !ui.FrecencyEnabled() {
+		return nil
+	}
+
+	store, err := LoadUsage()
+	if err != nil {
+		return err
+	}
+	store.Record(shortcut, now)
+	return store.Save()
+}
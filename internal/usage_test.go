@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageStoreRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store, err := LoadUsage()
+	if err != nil {
+		t.Fatalf("LoadUsage() without a store returned error: %v", err)
+	}
+	if len(store.Entries) != 0 {
+		t.Errorf("LoadUsage() without a store: got %d entries, want 0", len(store.Entries))
+	}
+
+	if err := RecordUsage(Shortcut{Display: "Ctrl+R", Keymap: "emacs"}, UIConfig{}); err != nil {
+		t.Fatalf("RecordUsage() returned error: %v", err)
+	}
+	if err := RecordUsage(Shortcut{Display: "Ctrl+R"}, UIConfig{}); err != nil {
+		t.Fatalf("RecordUsage() returned error: %v", err)
+	}
+
+	store, err = LoadUsage()
+	if err != nil {
+		t.Fatalf("LoadUsage() returned error: %v", err)
+	}
+	// Emacs and keymap-independent bindings share an entry, as in mergeKey
+	entry := store.Entries[mergeKey("", "Ctrl+R")]
+	if entry.Count != 2 {
+		t.Errorf("Ctrl+R count: got %d, want 2", entry.Count)
+	}
+	if time.Since(entry.LastUsed) > time.Minute {
+		t.Errorf("Ctrl+R last used: got %v, want about now", entry.LastUsed)
+	}
+
+	events, err := LoadUsageLog()
+	if err != nil {
+		t.Fatalf("LoadUsageLog() returned error: %v", err)
+	}
+	if len(events) != 2 || events[0].Key != "Ctrl+R" || events[0].Keymap != "emacs" || events[1].Keymap != "" {
+		t.Errorf("LoadUsageLog() = %+v, want both Ctrl+R selections", events)
+	}
+}
+
+func TestRecordUsageDisabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	off := false
+
+	if err := RecordUsage(Shortcut{Display: "Ctrl+R"}, UIConfig{Frecency: &off}); err != nil {
+		t.Fatalf("RecordUsage() returned error: %v", err)
+	}
+	store, _ := LoadUsage()
+	events, _ := LoadUsageLog()
+	if len(store.Entries) != 0 || len(events) != 1 {
+		t.Errorf("With frecency off: %d store entries and %d log events, want 0 and 1", len(store.Entries), len(events))
+	}
+
+	if err := RecordUsage(Shortcut{Display: "Ctrl+R"}, UIConfig{Stats: &off}); err != nil {
+		t.Fatalf("RecordUsage() returned error: %v", err)
+	}
+	store, _ = LoadUsage()
+	events, _ = LoadUsageLog()
+	if len(store.Entries) != 1 || len(events) != 1 {
+		t.Errorf("With stats off: %d store entries and %d log events, want 1 and 1", len(store.Entries), len(events))
+	}
+
+	if err := RecordUsage(Shortcut{Display: "Ctrl+R"}, UIConfig{Frecency: &off, Stats: &off}); err != nil {
+		t.Fatalf("RecordUsage() returned error: %v", err)
+	}
+	if store, _ := LoadUsage(); store.Entries[mergeKey("", "Ctrl+R")].Count != 1 {
+		t.Error("With frecency and stats off nothing should be recorded")
+	}
+}
+
+func TestUsageStoreFrecency(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store := &UsageStore{Entries: map[string]UsageEntry{
+		mergeKey("", "Ctrl+R"): {Count: 3, LastUsed: now.Add(-10 * time.Minute)},
+		mergeKey("", "Ctrl+A"): {Count: 3, LastUsed: now.Add(-30 * 24 * time.Hour)},
+		mergeKey("", "Ctrl+E"): {Count: 20, LastUsed: now.Add(-3 * 24 * time.Hour)},
+	}}
+
+	recent := store.Frecency(Shortcut{Display: "Ctrl+R"}, now)
+	old := store.Frecency(Shortcut{Display: "Ctrl+A"}, now)
+	frequent := store.Frecency(Shortcut{Display: "Ctrl+E"}, now)
+	if recent <= old {
+		t.Errorf("Recent use should score above old use: %v <= %v", recent, old)
+	}
+	if frequent <= recent {
+		t.Errorf("Frequent use should score above a few recent ones: %v <= %v", frequent, recent)
+	}
+	if unused := store.Frecency(Shortcut{Display: "Ctrl+K"}, now); unused != 0 {
+		t.Errorf("Unused shortcut should score 0, got %v", unused)
+	}
+
+	var disabled *UsageStore
+	if score := disabled.Frecency(Shortcut{Display: "Ctrl+R"}, now); score != 0 {
+		t.Errorf("Nil store should score 0, got %v", score)
+	}
+}
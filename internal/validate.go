@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Diagnostic is a problem found in the config file.
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var (
+	shortcutTypes   = []string{"widget", "command", "sequence", "insert"}
+	shortcutKeymaps = []string{"emacs", "viins", "vicmd", "visual"}
+	shortcutFields  = []string{"display", "description", "type", "target", "keymap", "category"}
+)
+
+// ValidateConfig checks the user config file and returns its diagnostics
+// sorted by line. A missing config file has no diagnostics.
+func ValidateConfig() ([]Diagnostic, error) {
+	_, content, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var builtins []Shortcut
+	if shell, err := detectShell(); err == nil {
+		builtins, _ = getBuiltinShortcuts(shell)
+	}
+
+	return validateConfig(content, builtins), nil
+}
+
+// validateConfig reports everything mergeShortcuts would silently ignore or
+// resolve arbitrarily. Built-ins decide whether a table needs a target.
+func validateConfig(content string, builtins []Shortcut) []Diagnostic {
+	var config Config
+	if _, err := toml.Decode(content, &config); err != nil {
+		diagnostic := Diagnostic{Severity: SeverityError, Message: err.Error()}
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			diagnostic.Line = parseErr.Position.Line
+			diagnostic.Message = parseErr.Message
+		}
+		return []Diagnostic{diagnostic}
+	}
+
+	known := make(map[string]bool)
+	for _, shortcut := range builtins {
+		known[mergeKey(shortcut.Keymap, shortcut.Display)] = true
+	}
+
+	lines := splitConfigLines(content)
+	blocks, _ := scanShortcutsTable(lines)
+
+	var diagnostics []Diagnostic
+	seen := make(map[string]string)
+	arrayIndex := make(map[string]int)
+	for _, block := range blocks {
+		value, ok := config.Shortcuts[block.key]
+		if !ok {
+			continue
+		}
+		if tables, ok := value.([]map[string]interface{}); ok {
+			// Each [[shortcuts.key]] block is the next table of the array
+			index := arrayIndex[block.key]
+			arrayIndex[block.key]++
+			if index >= len(tables) {
+				continue
+			}
+			value = tables[index]
+		}
+
+		report := func(line int, severity string, format string, args ...interface{}) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:     line + 1,
+				Severity: severity,
+				Key:      block.key,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+		fieldLine := func(field string) int {
+			for i := block.start; i < block.end; i++ {
+				if key, _, ok := splitKeyValue(strings.TrimSpace(lines[i])); ok && key == field {
+					return i
+				}
+			}
+			return block.start
+		}
+
+		keymap := ""
+		switch v := value.(type) {
+		case bool:
+			if v {
+				report(block.start, SeverityWarning, "'true' has no effect; use false to disable a shortcut")
+			}
+		case string:
+			if v == "" {
+				report(block.start, SeverityWarning, "empty description is ignored")
+			}
+		case map[string]interface{}:
+			for _, field := range sortedKeys(v) {
+				if !contains(shortcutFields, field) {
+					report(fieldLine(field), SeverityWarning, "unknown field '%s'%s", field, suggestion(field, shortcutFields))
+					continue
+				}
+				if _, ok := v[field].(string); !ok {
+					report(fieldLine(field), SeverityError, "field '%s' must be a string, got %s", field, tomlTypeName(v[field]))
+				}
+			}
+
+			keymap, _ = v["keymap"].(string)
+			if keymap != "" && !contains(shortcutKeymaps, keymap) {
+				report(fieldLine("keymap"), SeverityError, "unknown keymap '%s'%s", keymap, suggestion(keymap, shortcutKeymaps))
+			}
+
+			shortcutType, hasType := v["type"].(string)
+			if hasType && !contains(shortcutTypes, shortcutType) {
+				report(fieldLine("type"), SeverityError, "unknown type '%s'%s", shortcutType, suggestion(shortcutType, shortcutTypes))
+			}
+
+			if target, ok := v["target"].(string); ok && shortcutType == "sequence" {
+				if _, err := keySequenceBytes(target); err != nil {
+					report(fieldLine("target"), SeverityError, "%v", err)
+				}
+			}
+
+			if !known[mergeKey(keymap, block.key)] {
+				if _, ok := v["target"]; !ok {
+					report(block.start, SeverityError, "missing 'target' for a shortcut that is not built in")
+				}
+				if _, ok := v["type"]; !ok {
+					report(block.start, SeverityError, "missing 'type' for a shortcut that is not built in")
+				}
+			}
+		default:
+			report(block.start, SeverityError, "value must be a string, boolean or table, got %s", tomlTypeName(value))
+		}
+
+		merged := mergeKey(keymap, block.key)
+		if previous, ok := seen[merged]; ok {
+			report(block.start, SeverityWarning, "'%s' and '%s' both refer to %s; only one of them takes effect", previous, block.key, normalizeKey(block.key))
+		} else {
+			seen[merged] = block.key
+		}
+	}
+
+	if style := config.UI.KeyStyle; style != "" && !contains(KeyStyles, style) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Line:     tableFieldLine(lines, "ui", "key_style") + 1,
+			Severity: SeverityError,
+			Key:      "key_style",
+			Message:  fmt.Sprintf("unknown key style '%s'%s", style, suggestion(style, KeyStyles)),
+		})
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+
+	return diagnostics
+}
+
+// tableFieldLine returns the index of the line setting field in table, or
+// -1 when there is none.
+func tableFieldLine(lines []string, table string, field string) int {
+	current := ""
+	for i, line := range lines {
+		if match := tableHeaderPattern.FindStringSubmatch(line); match != nil {
+			current = match[1]
+			continue
+		}
+		if key, _, ok := splitKeyValue(strings.TrimSpace(line)); ok && current == table && key == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasErrors reports whether any diagnostic is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func tomlTypeName(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}, []map[string]interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "table"
+	default:
+		return "date"
+	}
+}
+
+// suggestion returns a "did you mean" hint for near misses.
+func suggestion(value string, candidates []string) string {
+	for _, candidate := range candidates {
+		if editDistance(strings.ToLower(value), candidate) <= 2 {
+			return fmt.Sprintf(" (did you mean '%s'?)", candidate)
+		}
+	}
+	return ""
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FormatDiagnostic renders a diagnostic as `path:line: severity: message`.
+func FormatDiagnostic(path string, diagnostic Diagnostic) string {
+	message := diagnostic.Message
+	if diagnostic.Key != "" {
+		message = fmt.Sprintf("%s: %s", diagnostic.Key, message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", path, diagnostic.Line, diagnostic.Severity, message)
+}
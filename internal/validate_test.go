@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	content := `[theme]
+name = "default"
+
+[shortcuts]
+gs = 5
+"^A" = "Start of line"
+"ctrl+a" = "Beginning of line"
+gd = { type = "comand", target = "git diff" }
+"Ctrl+E" = { description = "End of line" }
+"Ctrl+O" = { description = "Open" }
+"C-x" = true
+"M-s" = { type = "sequence", target = "C-a <f99>" }
+
+[shortcuts.gl]
+description = "Pretty log"
+tyep = "command"
+`
+	builtins := []Shortcut{
+		{Display: "Ctrl+A", Type: "widget", Target: "beginning-of-line", Keymap: "emacs"},
+		{Display: "Ctrl+E", Type: "widget", Target: "end-of-line", Keymap: "emacs"},
+	}
+
+	expected := []struct {
+		line     int
+		severity string
+		key      string
+		message  string
+	}{
+		{5, SeverityError, "gs", "got integer"},
+		{7, SeverityWarning, "ctrl+a", "both refer to Ctrl+A"},
+		{8, SeverityError, "gd", "unknown type 'comand' (did you mean 'command'?)"},
+		{10, SeverityError, "Ctrl+O", "missing 'target'"},
+		{10, SeverityError, "Ctrl+O", "missing 'type'"},
+		{11, SeverityWarning, "C-x", "'true' has no effect"},
+		{12, SeverityError, "M-s", `unknown key "f99"`},
+		{14, SeverityError, "gl", "missing 'target'"},
+		{14, SeverityError, "gl", "missing 'type'"},
+		{16, SeverityWarning, "gl", "unknown field 'tyep' (did you mean 'type'?)"},
+	}
+
+	diagnostics := validateConfig(content, builtins)
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("validateConfig() returned %d diagnostics, want %d: %+v", len(diagnostics), len(expected), diagnostics)
+	}
+
+	for i, want := range expected {
+		got := diagnostics[i]
+		if got.Line != want.line || got.Severity != want.severity || got.Key != want.key || !strings.Contains(got.Message, want.message) {
+			t.Errorf("diagnostic %d = %+v, want line %d %s %s containing %q", i, got, want.line, want.severity, want.key, want.message)
+		}
+	}
+
+	if !HasErrors(diagnostics) {
+		t.Error("HasErrors() should be true")
+	}
+}
+
+func TestValidateConfigSyntaxError(t *testing.T) {
+	diagnostics := validateConfig("[shortcuts]\ngs = \"git status\"\ngd = \n", nil)
+	if len(diagnostics) != 1 {
+		t.Fatalf("validateConfig() returned %d diagnostics, want 1", len(diagnostics))
+	}
+	if diagnostics[0].Line != 3 || diagnostics[0].Severity != SeverityError {
+		t.Errorf("syntax error diagnostic = %+v, want error on line 3", diagnostics[0])
+	}
+}
+
+func TestValidateConfigClean(t *testing.T) {
+	content := `[shortcuts]
+gs = "git status"
+"Ctrl+X Ctrl+E" = false
+"^G" = { description = "Git status", type = "command", target = "git status", keymap = "viins" }
+`
+	if diagnostics := validateConfig(content, nil); len(diagnostics) != 0 {
+		t.Errorf("validateConfig() on a clean config = %+v", diagnostics)
+	}
+	if HasErrors(nil) {
+		t.Error("HasErrors(nil) should be false")
+	}
+}
+
+func TestValidateConfigKeyStyle(t *testing.T) {
+	content := "[ui]\n\nkey_style = \"symbol\"\n"
+	diagnostics := validateConfig(content, nil)
+	if len(diagnostics) != 1 {
+		t.Fatalf("validateConfig() returned %d diagnostics, want 1: %+v", len(diagnostics), diagnostics)
+	}
+	if got := diagnostics[0]; got.Line != 3 || got.Severity != SeverityError || !strings.Contains(got.Message, "did you mean 'symbols'") {
+		t.Errorf("key_style diagnostic = %+v", got)
+	}
+}
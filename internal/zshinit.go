@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateZshBindings returns the zsh code that binds every custom shortcut
+// from the config, so the config drives the live key bindings as well as the
+// picker.
+func GenerateZshBindings() (string, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	builtins, err := getBuiltinShortcuts("zsh")
+	if err != nil {
+		return "", err
+	}
+
+	return zshBindings(builtins, mergeShortcuts(builtins, config)), nil
+}
+
+// zshBindings emits `bindkey` lines, plus `zle -N` widgets for commands and
+// insertions, for custom shortcuts that differ from the built-in binding for
+// their key.
+func zshBindings(builtins []Shortcut, shortcuts []Shortcut) string {
+	existing := make(map[string]Shortcut)
+	for _, shortcut := range builtins {
+		existing[mergeKey(shortcut.Keymap, shortcut.Display)] = shortcut
+	}
+
+	var b strings.Builder
+	b.WriteString("# Custom shortcuts from ~/.config/shortcutter/config.toml\n")
+
+	commands, inserts := 0, 0
+	for _, shortcut := range shortcuts {
+		if !shortcut.IsCustom {
+			continue
+		}
+		if builtin, ok := existing[mergeKey(shortcut.Keymap, shortcut.Display)]; ok &&
+			builtin.Type == shortcut.Type && builtin.Target == shortcut.Target {
+			// Only the description was overridden
+			continue
+		}
+
+		sequence, err := ParseKeySequence(shortcut.Display)
+		if err != nil {
+			fmt.Fprintf(&b, "# skipped %s: %v\n", shortcut.Display, err)
+			continue
+		}
+		keys := sequence.Bytes()
+		if sequence[0].isPlain() && shortcut.Keymap != "vicmd" && shortcut.Keymap != "visual" {
+			fmt.Fprintf(&b, "# skipped %s: plain characters are typed, not bound, outside vi command mode\n", shortcut.Display)
+			continue
+		}
+
+		bindkey := "bindkey"
+		if shortcut.Keymap != "" {
+			bindkey += " -M " + shortcut.Keymap
+		}
+
+		switch shortcut.Type {
+		case "widget":
+			target := shellQuote(shortcut.Target)
+			fmt.Fprintf(&b, "zle -la %s || { autoload -Uz %s; zle -N %s }\n", target, target, target)
+			fmt.Fprintf(&b, "%s %s %s\n", bindkey, zshQuoteKeys(keys), target)
+		case "command":
+			commands++
+			widget := fmt.Sprintf("_shortcutter_command_%d", commands)
+			fmt.Fprintf(&b, "%s() {\n    zle push-input\n    BUFFER=%s\n    zle accept-line\n}\n", widget, shellQuote(shortcut.Target))
+			fmt.Fprintf(&b, "zle -N %s\n", widget)
+			fmt.Fprintf(&b, "%s %s %s\n", bindkey, zshQuoteKeys(keys), widget)
+		case "insert":
+			inserts++
+			widget := fmt.Sprintf("_shortcutter_insert_%d", inserts)
+			fmt.Fprintf(&b, "%s() {\n    LBUFFER+=%s\n}\n", widget, shellQuote(shortcut.Target))
+			fmt.Fprintf(&b, "zle -N %s\n", widget)
+			fmt.Fprintf(&b, "%s %s %s\n", bindkey, zshQuoteKeys(keys), widget)
+		case "sequence":
+			sequence, err := keySequenceBytes(shortcut.Target)
+			if err != nil {
+				fmt.Fprintf(&b, "# skipped %s: %v\n", shortcut.Display, err)
+				continue
+			}
+			fmt.Fprintf(&b, "%s -s %s %s\n", bindkey, zshQuoteKeys(keys), zshQuoteKeys(sequence))
+		default:
+			fmt.Fprintf(&b, "# skipped %s: unknown type '%s'\n", shortcut.Display, shortcut.Type)
+		}
+	}
+
+	return b.String()
+}
+
+// zshQuoteKeys renders keys in bindkey notation, also escaping the characters
+// zsh would expand inside double quotes.
+func zshQuoteKeys(keys string) string {
+	quoted := quoteBindkeyString(keys)
+	quoted = strings.ReplaceAll(quoted, "$", `\$`)
+	return strings.ReplaceAll(quoted, "`", "\\`")
+}
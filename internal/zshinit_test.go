@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZshBindings(t *testing.T) {
+	builtins := []Shortcut{
+		{Display: "Ctrl+A", Description: "Beginning of line", Type: "widget", Target: "beginning-of-line", Keymap: "emacs"},
+	}
+	config := &Config{Shortcuts: map[string]interface{}{
+		"Ctrl+A":   "Start of line",
+		"Ctrl+X g": map[string]interface{}{"type": "command", "target": "git status"},
+		"C-x C-e":  map[string]interface{}{"type": "widget", "target": "edit-command-line"},
+		"M-s":      map[string]interface{}{"type": "sequence", "target": "C-a sudo Space"},
+		"gx":       map[string]interface{}{"type": "command", "target": "git x", "keymap": "vicmd"},
+		"gs":       map[string]interface{}{"type": "command", "target": "git status"},
+		"M-g":      map[string]interface{}{"type": "insert", "target": "| grep "},
+		"M-x":      map[string]interface{}{"type": "widget", "target": "my widget; rm"},
+	}}
+
+	output := zshBindings(builtins, mergeShortcuts(builtins, config))
+
+	expected := []string{
+		"BUFFER='git status'\n",
+		`bindkey "^Xg" _shortcutter_command_`,
+		"zle -la 'edit-command-line' || { autoload -Uz 'edit-command-line'; zle -N 'edit-command-line' }\n",
+		`bindkey "^X^E" 'edit-command-line'`,
+		`bindkey -s "^[s" "^Asudo "`,
+		"LBUFFER+='| grep '\n",
+		`bindkey "^[g" _shortcutter_insert_1`,
+		`bindkey -M vicmd "gx" _shortcutter_command_`,
+		"# skipped gs:",
+		`bindkey "^[x" 'my widget; rm'`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("zshBindings() missing %q:\n%s", want, output)
+		}
+	}
+
+	if strings.Contains(output, "beginning-of-line") {
+		t.Errorf("zshBindings() rebound a description-only override:\n%s", output)
+	}
+}
@@ -1,25 +1,131 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"shortcutter/internal"
 )
 
 func main() {
-	shortcuts, styles, err := internal.LoadShortcutsAndTheme()
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	runPicker(os.Args[1:])
+}
+
+// runPicker shows the interactive picker and prints the selection for the
+// shell integration.
+func runPicker(args []string) {
+	flags := flag.NewFlagSet("shortcutter", flag.ExitOnError)
+	bindingsPath, aliasesPath, functionsPath := shellStateFlags(flags)
+	keymap := flags.String("keymap", "", "start the picker in `keymap` (emacs, viins, vicmd or visual)")
+	format := flags.String("format", "sh", "print the selection as `dialect` assignments (sh or fish)")
+	flags.Usage = usage(flags)
+	flags.Parse(args)
+
+	if !internal.IsHandoffFormat(*format) {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format '%s' - use sh or fish\n", *format)
+		os.Exit(internal.ExitError)
+	}
+
+	state, err := readShellState(*bindingsPath, *aliasesPath, *functionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading shell state: %v\n", err)
+		os.Exit(internal.ExitError)
+	}
+
+	shortcuts, styles, err := internal.LoadShortcutsAndThemeFromShell(state)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading shortcuts and theme: %v\n", err)
-		os.Exit(1)
+		os.Exit(internal.ExitError)
 	}
 
-	selected, selectedKey, err := internal.ShowUI(shortcuts, styles)
+	ui := internal.LoadUIConfig()
+	var usage *internal.UsageStore
+	if ui.FrecencyEnabled() {
+		if usage, err = internal.LoadUsage(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring usage history: %v\n", err)
+		}
+	}
+
+	selected, selectedKey, err := internal.ShowUI(shortcuts, styles, *keymap, ui.KeyStyle, usage)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error showing UI: %v\n", err)
-		os.Exit(1)
+		os.Exit(internal.ExitError)
+	}
+
+	if selected == nil {
+		os.Exit(internal.ExitCancelled)
+	}
+
+	// Keys are looked up in the shortcut's own keymap, which differs from
+	// the starting one after switching keymaps with Ctrl+K
+	resolveKeymap := *keymap
+	if selected.Keymap != "" {
+		resolveKeymap = selected.Keymap
+	}
+
+	handoff := internal.NewHandoff(*selected, selectedKey)
+	handoff.Keys, err = internal.ResolveKeys(*selected, state.Bindings, resolveKeymap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving keys for '%s': %v\n", selected.Display, err)
+		os.Exit(internal.ExitError)
 	}
+	if *format == "fish" && selected.Type == "sequence" {
+		// fish can't replay raw keys, so hand over what they are bound to
+		handoff.Target = internal.FishSequenceTarget(*selected, state.Bindings)
+	}
+
+	output, err := handoff.Format(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting selection: %v\n", err)
+		os.Exit(internal.ExitError)
+	}
+	fmt.Print(output)
+
+	if err := internal.RecordUsage(*selected, ui); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record usage: %v\n", err)
+	}
+}
+
+// shellStateFlags registers the flags used to pass live shell state in.
+func shellStateFlags(flags *flag.FlagSet) (*string, *string, *string) {
+	bindingsPath := flags.String("bindings", "", "read key bindings (bindkey -L or bind -p) from `file`")
+	aliasesPath := flags.String("aliases", "", "read aliases (alias -L or alias -p) from `file`")
+	functionsPath := flags.String("functions", "", "read function definitions from `file`")
+	return bindingsPath, aliasesPath, functionsPath
+}
+
+// readShellState loads the shell dumps passed in by the integration script.
+func readShellState(bindingsPath, aliasesPath, functionsPath string) (internal.ShellState, error) {
+	var state internal.ShellState
+	var err error
 
-	if selected != nil {
-		fmt.Printf("%s:%s:%s\n", selectedKey, selected.Type, selected.Target)
+	if state.Bindings, err = readOptionalFile(bindingsPath); err != nil {
+		return state, err
+	}
+	if state.Aliases, err = readOptionalFile(aliasesPath); err != nil {
+		return state, err
+	}
+	if state.Functions, err = readOptionalFile(functionsPath); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+func readOptionalFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
 	}
+	return string(data), nil
 }
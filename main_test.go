@@ -2,7 +2,9 @@ package main
 
 import (
 	"shortcutter/internal"
+	"strings"
 	"testing"
+	"text/template"
 )
 
 func TestDetectShortcuts(t *testing.T) {
@@ -52,3 +54,80 @@ func TestMainIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegrationScripts(t *testing.T) {
+	// Every shell names the function the trigger key runs after the widget
+	definitions := map[string]string{
+		"zsh":  "\nmy_widget() {\n",
+		"bash": "\nmy_widget() {\n",
+		"fish": "\nfunction my_widget\n",
+	}
+	for _, shell := range []string{"zsh", "bash", "fish"} {
+		script, err := template.ParseFS(integrationScripts, "shortcutter."+shell)
+		if err != nil {
+			t.Errorf("parsing %s integration: %v", shell, err)
+			continue
+		}
+
+		var output strings.Builder
+		options := integrationOptions{Key: "KEY", Widget: "my_widget"}
+		if err := script.Execute(&output, options); err != nil {
+			t.Errorf("executing %s integration: %v", shell, err)
+			continue
+		}
+		if !strings.Contains(output.String(), "my_widget") || !strings.Contains(output.String(), "KEY") {
+			t.Errorf("%s integration does not use the key and widget options", shell)
+		}
+		if !strings.Contains(output.String(), definitions[shell]) || strings.Contains(output.String(), "shortcutter_widget") {
+			t.Errorf("%s integration should define its function as my_widget", shell)
+		}
+	}
+}
+
+func TestFishIntegrationRunsSequences(t *testing.T) {
+	script, err := template.ParseFS(integrationScripts, "shortcutter.fish")
+	if err != nil {
+		t.Fatalf("parsing fish integration: %v", err)
+	}
+	var output strings.Builder
+	if err := script.Execute(&output, integrationOptions{Key: `\c_`, Widget: "shortcutter_widget"}); err != nil {
+		t.Fatalf("executing fish integration: %v", err)
+	}
+
+	// The sequence branch runs every input function or command the picker
+	// resolved the keys to, not just a hardcoded Ctrl+C
+	rendered := output.String()
+	start := strings.Index(rendered, "case sequence")
+	end := strings.Index(rendered, "case command")
+	if start < 0 || end < start {
+		t.Fatalf("fish integration has no sequence branch:\n%s", rendered)
+	}
+	branch := rendered[start:end]
+	for _, want := range []string{"string split -n '; ' -- $target", "commandline -f $action", "eval $action"} {
+		if !strings.Contains(branch, want) {
+			t.Errorf("fish sequence branch is missing %q:\n%s", want, branch)
+		}
+	}
+	if strings.Contains(branch, "case C-c") {
+		t.Errorf("fish sequence branch still special-cases Ctrl+C:\n%s", branch)
+	}
+}
+
+func TestInKeymap(t *testing.T) {
+	shortcuts := []internal.Shortcut{
+		{Display: "Ctrl+A", Keymap: "emacs"},
+		{Display: "x", Keymap: "vicmd"},
+		{Display: "gs"},
+	}
+
+	var displays []string
+	for _, shortcut := range inKeymap(shortcuts, "vicmd") {
+		displays = append(displays, shortcut.Display)
+	}
+	if got := strings.Join(displays, ","); got != "x,gs" {
+		t.Errorf("inKeymap(vicmd) = %s, want x,gs", got)
+	}
+	if got := inKeymap(shortcuts, ""); len(got) != 3 {
+		t.Errorf("inKeymap(\"\") kept %d shortcuts, want all 3", len(got))
+	}
+}
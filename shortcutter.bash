@@ -0,0 +1,106 @@
+#!/bin/bash
+
+# Shortcutter bash integration
+# Printed by `shortcutter init bash`; load it from your .bashrc with:
+#   eval "$(shortcutter init bash)"
+
+{{.Widget}}() {
+    # Save the current command line state
+    local saved_line="$READLINE_LINE"
+    local saved_point="$READLINE_POINT"
+
+    # Reset the replay key so nothing runs unless a widget is selected
+    bind '"\C-x\C-_b": redraw-current-line'
+
+    # Move to next line (fzf pattern - don't clear current line)
+    echo
+
+    # Run shortcutter with the live key bindings, aliases and functions, listing
+    # the file and line each function was defined at so its comments can be read
+    local result
+    result=$(shortcutter \
+        --bindings <(bind -p; bind -X) \
+        --aliases <(alias -p) \
+        --functions <(shopt -s extdebug
+            for fn in $(compgen -A function); do
+                read -r name line file < <(declare -F "$fn")
+                printf '%s\t%s\t%s\n' "$name" "$file" "$line"
+            done) 2>/dev/null)
+    local exit_code=$?
+
+    # Cancelled leaves the line untouched; anything else but success is an error
+    if (( exit_code == 1 )); then
+        return
+    elif (( exit_code != 0 )); then
+        echo "shortcutter: failed to load shortcuts" >&2
+        return
+    fi
+
+    # The result is a list of shell-quoted SHORTCUTTER_* assignments
+    local SHORTCUTTER_PROTOCOL SHORTCUTTER_KEY SHORTCUTTER_TYPE SHORTCUTTER_TARGET SHORTCUTTER_KEYMAP SHORTCUTTER_KEYS
+    eval "$result"
+    if [[ "$SHORTCUTTER_PROTOCOL" != 1 ]]; then
+        echo "shortcutter: unsupported protocol version '$SHORTCUTTER_PROTOCOL'" >&2
+        return
+    fi
+
+    local key="$SHORTCUTTER_KEY"
+    local type="$SHORTCUTTER_TYPE"
+    local target="$SHORTCUTTER_TARGET"
+
+    # Determine action based on key press and context
+    local should_populate=false
+
+    if [[ "$key" == "tab" ]]; then
+        # Tab always populates (for command types only)
+        should_populate=true
+    elif [[ "$key" == "enter" && "$type" == "command" && -n "$saved_line" ]]; then
+        # Enter with existing line content should populate
+        should_populate=true
+    fi
+
+    if [[ "$type" == "widget" ]]; then
+        # Readline functions can't be called from a bind -x handler, so point
+        # the second half of the trigger macro at the selected function
+        bind "\"\\C-x\\C-_b\": $target"
+    elif [[ "$type" == "sequence" ]]; then
+        # Replay the keys shortcutter decoded from the target as a readline macro
+        local keys
+        printf -v keys '%b' "$SHORTCUTTER_KEYS"
+        keys="${keys//\\/\\\\}"
+        keys="${keys//\"/\\\"}"
+        keys="${keys//$'\n'/\\n}"
+        bind "\"\\C-x\\C-_b\": \"$keys\""
+    elif [[ "$type" == "insert" ]]; then
+        # Insertions never run: put the text at the cursor, after a space if
+        # the word before it isn't finished
+        local before="${saved_line:0:saved_point}"
+        if [[ -n "$before" && "$before" != *" " ]]; then
+            before+=" "
+        fi
+        READLINE_LINE="$before$target${saved_line:saved_point}"
+        READLINE_POINT=$(( ${#before} + ${#target} ))
+    elif [[ "$type" == "command" ]]; then
+        if [[ "$should_populate" == "true" ]]; then
+            # Populate command into the line
+            if [[ -n "$saved_line" && "$saved_line" != *" " ]]; then
+                READLINE_LINE="$saved_line $target"
+            else
+                READLINE_LINE="$saved_line$target"
+            fi
+            READLINE_POINT=${#READLINE_LINE}
+        else
+            # Execute command immediately, then restore state
+            eval "$target"
+            READLINE_LINE="$saved_line"
+            READLINE_POINT="$saved_point"
+        fi
+    fi
+}
+
+# Stage one runs shortcutter, stage two replays the selected readline function
+bind -x '"\C-x\C-_a": {{.Widget}}'
+bind '"\C-x\C-_b": redraw-current-line'
+
+# Bind the trigger key (Ctrl+/ by default, "\C-_" in readline) to both stages
+bind '{{.Key}}: "\C-x\C-_a\C-x\C-_b"'
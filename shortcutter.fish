@@ -0,0 +1,110 @@
+# Shortcutter fish integration
+# Printed by `shortcutter init fish`; load it from your config.fish with:
+#   shortcutter init fish | source
+
+function {{.Widget}}
+    # Save the current command line state
+    set -l saved_buffer (commandline)
+    set -l saved_cursor (commandline -C)
+
+    # Move to next line (fzf pattern - don't clear current line)
+    echo
+
+    # Public user functions only; fish's own helpers are noise in the picker
+    set -l user_functions (functions --names | string match -v -r '^(_|fish_)')
+
+    # Run shortcutter with the live key bindings, aliases and functions
+    set -l result (shortcutter \
+        --format fish \
+        --bindings (bind | psub) \
+        --aliases (alias | psub) \
+        --functions (functions $user_functions | psub) 2>/dev/null)
+    set -l exit_code $status
+
+    # The result is a list of `set -g SHORTCUTTER_* value` commands
+    if test $exit_code -eq 0
+        string join \n -- $result | source
+        if test "$SHORTCUTTER_PROTOCOL" != 1
+            echo "shortcutter: unsupported protocol version '$SHORTCUTTER_PROTOCOL'" >&2
+            set exit_code 2
+        end
+    else if test $exit_code -ne 1
+        echo "shortcutter: failed to load shortcuts" >&2
+    end
+
+    if test $exit_code -eq 0
+        set -l key $SHORTCUTTER_KEY
+        set -l type $SHORTCUTTER_TYPE
+        set -l target $SHORTCUTTER_TARGET
+        set -e SHORTCUTTER_PROTOCOL SHORTCUTTER_KEY SHORTCUTTER_TYPE SHORTCUTTER_TARGET SHORTCUTTER_KEYMAP SHORTCUTTER_KEYS
+
+        # Determine action based on key press and context
+        set -l should_populate false
+
+        if test "$key" = tab
+            # Tab always populates (for command types only)
+            set should_populate true
+        else if test "$key" = enter -a "$type" = command -a -n "$saved_buffer"
+            # Enter with existing buffer content should populate
+            set should_populate true
+        end
+
+        # Execute based on type and action
+        switch $type
+            case widget
+                # Restore buffer first, then run the input function or fish function
+                commandline -r -- $saved_buffer
+                commandline -C $saved_cursor
+                if contains -- $target (bind --function-names)
+                    commandline -f $target
+                else
+                    eval $target
+                end
+            case sequence
+                # fish can't replay raw keys, so the target is what fish binds
+                # them to: input functions or commands separated by "; "
+                commandline -r -- $saved_buffer
+                commandline -C $saved_cursor
+                for action in (string split -n '; ' -- $target)
+                    if contains -- $action (bind --function-names)
+                        commandline -f $action
+                    else
+                        eval $action
+                    end
+                end
+            case insert
+                # Insertions never run: put the text at the cursor, after a
+                # space if the word before it isn't finished
+                commandline -r -- $saved_buffer
+                commandline -C $saved_cursor
+                set -l before (commandline -c)
+                if test -n "$before"; and not string match -q -- '* ' "$before"
+                    commandline -i -- " $target"
+                else
+                    commandline -i -- $target
+                end
+            case command
+                if test "$should_populate" = true
+                    # Populate command into buffer
+                    if test -n "$saved_buffer"; and not string match -q -- '* ' $saved_buffer
+                        commandline -r -- "$saved_buffer $target"
+                    else
+                        commandline -r -- "$saved_buffer$target"
+                    end
+                    commandline -f end-of-line
+                else
+                    # Execute command immediately, then restore state
+                    eval $target
+                    commandline -r -- $saved_buffer
+                    commandline -C $saved_cursor
+                end
+        end
+    end
+
+    # Always repaint the prompt at the end
+    commandline -f repaint
+end
+
+# Bind the trigger key (Ctrl+/ by default, "\c_" in fish) to the widget
+bind {{.Key}} {{.Widget}}
+bind -M insert {{.Key}} {{.Widget}}